@@ -3,6 +3,9 @@ package internal
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,17 +16,29 @@ import (
 
 // View names
 const (
-	PathViewName     = "path"
-	FilesViewName    = "files"
-	ContentViewName  = "content"
-	HelpViewName     = "help"
-	FilterViewName   = "filter"
-	StatusViewName   = "status"
-	CacheViewName    = "cache"
-	ConfirmViewName  = "confirm"
-	DefaultExcludes  = ".git/,node_modules/"
-	MaxSelectedFiles = 50
-	MaxFileSizeBytes = 100 * 1024
+	PathViewName         = "path"
+	FilesViewName        = "files"
+	ContentViewName      = "content"
+	HelpViewName         = "help"
+	FilterViewName       = "filter"
+	StatusViewName       = "status"
+	CacheViewName        = "cache"
+	SkippedViewName      = "skipped"
+	TransformsViewName   = "transforms"
+	RefsPromptViewName   = "refsPrompt"
+	ReferencesViewName   = "references"
+	TemplatesViewName    = "templates"
+	InstructionViewName  = "instruction"
+	ExportPromptViewName = "exportPrompt"
+	HistoryViewName      = "history"
+	CopyPreviewViewName  = "copyPreview"
+	OrderViewName        = "order"
+	TruncPromptViewName  = "truncPrompt"
+	ConfirmViewName      = "confirm"
+	DividerViewName      = "divider"
+	DefaultExcludes      = ".git/,node_modules/"
+	MaxSelectedFiles     = 50
+	MaxFileSizeBytes     = 100 * 1024
 )
 
 // FilterMode defines whether the filter includes or excludes patterns.
@@ -34,40 +49,224 @@ const (
 	IncludeMode                   // Filter includes *only* matching patterns
 )
 
+// SymlinkPolicy controls how ListFiles treats symbolic links.
+type SymlinkPolicy string
+
+const (
+	SymlinkSkip   SymlinkPolicy = "skip"   // Leave symlinks out (reported in the Skipped view)
+	SymlinkList   SymlinkPolicy = "list"   // List the link itself; its content is the link target
+	SymlinkFollow SymlinkPolicy = "follow" // Follow links, with loop detection and root-escape protection (default)
+)
+
+// ParseSymlinkPolicy converts a flag or cache value into a SymlinkPolicy.
+func ParseSymlinkPolicy(value string) (SymlinkPolicy, error) {
+	switch policy := SymlinkPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case SymlinkSkip, SymlinkList, SymlinkFollow:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid symlink policy %q (expected skip, list or follow)", value)
+	}
+}
+
+// SkipReason describes why a path was left out of the Files view.
+type SkipReason string
+
+const (
+	SkipGitignore      SkipReason = "gitignore"       // Matched a .gitignore rule
+	SkipDefaultExclude SkipReason = "default-exclude" // Matched DefaultExcludes
+	SkipMetadata       SkipReason = "metadata"        // .git directory or the .gitignore file itself
+	SkipBinary         SkipReason = "binary"          // Content sniffing did not detect text
+	SkipPermission     SkipReason = "permission"      // Permission denied while walking or opening
+	SkipReadError      SkipReason = "read-error"      // Any other error while walking or reading
+	SkipSymlink        SkipReason = "symlink"         // Symlink rejected by the symlink policy
+	SkipFilter         SkipReason = "filter"          // Removed by the user's include/exclude filter
+)
+
+// skipReasonOrder is the order in which groups are listed in the Skipped view.
+var skipReasonOrder = []SkipReason{
+	SkipGitignore,
+	SkipDefaultExclude,
+	SkipMetadata,
+	SkipBinary,
+	SkipPermission,
+	SkipReadError,
+	SkipSymlink,
+	SkipFilter,
+}
+
+// skipReasonLabels are the human readable group headers for the Skipped view.
+var skipReasonLabels = map[SkipReason]string{
+	SkipGitignore:      "Ignored by .gitignore",
+	SkipDefaultExclude: "Default excludes",
+	SkipMetadata:       "Repository metadata",
+	SkipBinary:         "Binary (not detected as text)",
+	SkipPermission:     "Permission denied",
+	SkipReadError:      "Read errors",
+	SkipSymlink:        "Symlinks not followed",
+	SkipFilter:         "Removed by filter",
+}
+
+// SkippedFile records why a path was rejected during discovery or filtering.
+// Directory paths carry a trailing slash; their contents were never visited.
+type SkippedFile struct {
+	Reason SkipReason
+	Detail string // Optional extra context (error text, content type, ...)
+}
+
+// skippedViewLine is one rendered row of the Skipped view. Group headers
+// have an empty path and cannot be focused by the cursor.
+type skippedViewLine struct {
+	text string
+	path string
+}
+
+// Root is one directory scanned in a session. When several roots are open,
+// their entries are shown and bundled as "Label/relpath".
+type Root struct {
+	Dir              string // Absolute path
+	Label            string // Unique short name, derived from the directory name
+	gitignoreMatcher gitignore.GitIgnore
+}
+
+// ignores reports whether relPath (relative to r.Dir, slash format) is matched
+// by the root's .gitignore. Matching is relative to the root rather than the
+// working directory, which the matcher's Ignore method would use.
+func (r Root) ignores(relPath string, isDir bool) bool {
+	if r.gitignoreMatcher == nil {
+		return false
+	}
+	match := r.gitignoreMatcher.Relative(relPath, isDir)
+	return match != nil && match.Ignore()
+}
+
+// newRoots builds uniquely labelled roots for the given absolute directories.
+func newRoots(rootDirs []string) []Root {
+	roots := make([]Root, 0, len(rootDirs))
+	used := make(map[string]bool, len(rootDirs))
+	for _, dir := range rootDirs {
+		base := filepath.Base(dir)
+		if base == string(filepath.Separator) || base == "." {
+			base = "root"
+		}
+		label := base
+		for n := 2; used[label]; n++ {
+			label = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[label] = true
+		roots = append(roots, Root{Dir: dir, Label: label})
+	}
+	return roots
+}
+
+// sessionCacheKey returns the cache key for a set of roots. A single root keeps
+// using its directory as the key, so existing cache entries stay valid.
+func sessionCacheKey(rootDirs []string) string {
+	if len(rootDirs) == 1 {
+		return rootDirs[0]
+	}
+	sorted := append([]string(nil), rootDirs...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, string(os.PathListSeparator))
+}
+
+// LineRange is an inclusive, 1-based range of lines within a file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
 // --- Cache Structures ---
 
-// DirectoryCache holds the cached settings for a specific directory.
+// DirectoryCache holds the cached settings for a specific directory (or set of
+// directories, see sessionCacheKey).
 type DirectoryCache struct {
 	Includes   string     `json:"includes"`
 	Excludes   string     `json:"excludes"`
 	LastOpened time.Time  `json:"lastOpened"`
 	FilterMode FilterMode `json:"filterMode"`
+	// ForceIncludes lists skipped paths the user explicitly pulled back in.
+	ForceIncludes []string `json:"forceIncludes,omitempty"`
+	// SymlinkPolicy is the last policy chosen with --symlinks for this directory.
+	SymlinkPolicy SymlinkPolicy `json:"symlinkPolicy,omitempty"`
+	// LineNumbers prefixes bundled lines with their line numbers.
+	LineNumbers bool `json:"lineNumbers,omitempty"`
+	// Transforms are the content transforms applied to bundled files.
+	Transforms Transforms `json:"transforms"`
+	// Instruction is the last task description added to the bundle.
+	Instruction string `json:"instruction,omitempty"`
+	// InstructionAfter places the instruction after the files instead of before.
+	InstructionAfter bool `json:"instructionAfter,omitempty"`
+	// Pinned maps files moved in the Order view to their bundle positions.
+	Pinned map[string]int `json:"pinned,omitempty"`
+	// OrderStrategy orders selected files missing from Pinned.
+	OrderStrategy OrderStrategy `json:"orderStrategy,omitempty"`
+	// Truncations are the per-file truncation rules set in the Files view,
+	// in the form ParseTruncation accepts.
+	Truncations map[string]string `json:"truncations,omitempty"`
+	// Layout is the arrangement of the Files and Content panes.
+	Layout *PaneLayout `json:"layout,omitempty"`
 }
 
 type AppCache map[string]DirectoryCache
 
 type App struct {
-	g                *gocui.Gui
-	rootDir          string
-	fileList         []string // Currently displayed list of relative file paths
-	allFiles         []string // All discovered files before filtering
-	selectedFiles    map[string]bool
-	gitignoreMatcher gitignore.GitIgnore
-	currentLine      int // Cursor position in the fileList view
-	showHelp         bool
-	filterMode       FilterMode
-	excludes         string // Comma-separated patterns to exclude
-	includes         string // Comma-separated patterns to include
-	mutex            sync.Mutex
-	tokenizer        *tiktoken.Tiktoken
+	g             *gocui.Gui
+	roots         []Root
+	fileList      []string // Currently displayed list of display paths (see displayPrefix)
+	allFiles      []string // All discovered files before filtering
+	selectedFiles map[string]bool
+	currentLine   int // Cursor position in the fileList view
+	showHelp      bool
+	filterMode    FilterMode
+	excludes      string // Comma-separated patterns to exclude
+	includes      string // Comma-separated patterns to include
+	mutex         sync.Mutex
+	tokenizer     *tiktoken.Tiktoken
 
 	// --- Live Preview State (Content View) ---
-	currentlyPreviewedFile string // File path for the live content view preview
-	contentViewOriginY     int    // Scroll position for the content view
+	currentlyPreviewedFile string       // File path for the live content view preview
+	contentViewOriginY     int          // Scroll position for the content view
+	highlighter            *Highlighter // Syntax highlighting of the preview, nil if disabled
+	theme                  Theme        // Colours of the UI, set before it starts
+
+	// --- Line Range State ---
+	lineRanges   map[string][]LineRange // Partial selections: only these lines are bundled
+	visualActive bool                   // A range is being marked in the content view
+	visualAnchor int                    // 0-based line where marking started
+	visualCursor int                    // 0-based line currently under the marking cursor
+
+	// --- Render Mode State ---
+	renderModes map[string]RenderMode // Files not bundled in full (absent = RenderFull)
+
+	// --- Truncation State ---
+	truncation       Truncation            // Rule for files without an override
+	truncations      map[string]Truncation // Per-file overrides from the Files view
+	showTruncPrompt  bool                  // Truncation prompt is open
+	truncPromptPath  string                // File the prompt applies to
+	truncationCutKey string                // State truncationCut was computed for (see truncationMarkers)
+	truncationCut    map[string]bool       // Selected files the global rule cuts
+
+	// --- Bundle Options ---
+	lineNumbers        bool                // Prefix bundled (and previewed) lines with line numbers
+	transforms         Transforms          // Content transforms applied before bundling
+	previewTransformed bool                // Content view shows the transformed file
+	showTransforms     bool                // Transforms overlay is open
+	importDepth        int                 // Import hops followed by ExpandGoImports
+	instruction        string              // Task description copied with the bundle
+	instructionAfter   bool                // Append the instruction instead of prepending it
+	showInstruction    bool                // Instruction editor is open
+	fileSizes          map[string]fileSize // Bundled sizes of files for the status bar (see bundleSize)
+
+	// --- Order State ---
+	pinnedFiles   map[string]int // Bundle positions of files moved in the Order view (see selectedInOrder)
+	orderStrategy OrderStrategy  // Order of selected files missing from pinnedFiles
+	showOrder     bool           // Order view is open
+	orderCursor   int
 
 	// --- Cache State ---
 	cache         AppCache
 	cacheFilePath string
+	cacheKey      string // Key of this session's entry in cache (see sessionCacheKey)
 
 	// --- Cache View State ---
 	showCacheView                  bool
@@ -75,23 +274,89 @@ type App struct {
 	cacheViewOriginY               int
 	awaitingCacheClearConfirmation bool
 
+	// --- Skipped Files State ---
+	skippedFiles  map[string]SkippedFile // Paths rejected while walking the directory
+	filterSkipped map[string]SkippedFile // Paths removed by applyFilters
+	forceIncluded map[string]bool        // Paths included regardless of skip reason
+	expandedDirs  map[string]bool        // Skipped directories listing their files in the Skipped view
+
+	// --- Symlink State ---
+	symlinkPolicy      SymlinkPolicy
+	allowSymlinkEscape bool              // Follow links whose target lies outside rootDir
+	symlinkedFiles     map[string]string // Files reached through a symlink -> link target
+	listedSymlinks     map[string]string // Links listed as entries under SymlinkList -> link target
+
+	// --- Skipped View State ---
+	showSkippedView   bool
+	skippedViewLines  []skippedViewLine
+	skippedViewCursor int
+
+	// --- Sensitive Files State ---
+	pendingSensitive string // Sensitive file awaiting a confirming second Space or mark
+
+	// --- References State ---
+	showRefsPrompt bool
+	refsPromptText string // Initial text of the identifier prompt
+	showReferences bool
+	refsIdent      string
+	refsHits       []referenceHit
+
+	// --- Template Picker State ---
+	showTemplates   bool
+	promptTemplates []promptTemplate
+	templatesCursor int // 0 is the plain bundle, i is promptTemplates[i-1]
+
+	// --- History State ---
+	showExportPrompt bool
+	exportPromptText string // Initial text of the export path prompt
+	showHistory      bool
+	historyEntries   []historyEntry
+	historyCursor    int
+	historyDiff      string // Diff shown instead of the list, "" for the list
+
+	// --- Copy Preview State ---
+	showCopyPreview bool
+	previewText     string // Exactly what CopyAllSelected would copy
+	previewTitle    string
+
 	// --- Loading State ---
 	isLoading     bool
 	loadingError  error
 	loadStartTime time.Time
 
+	// --- Keymap State ---
+	bindings []activeBinding // Set by SetKeymap, the defaults until then
+
+	// --- Pane Layout State ---
+	layout          PaneLayout  // Persisted in the cache
+	stackWidth      int         // Terminal width below which the auto layout stacks the panes
+	draggingDivider bool        // The divider is being dragged with the mouse
+	dividerColumn   int         // Pointer column of the last drag event handled
+	layoutSaveTimer *time.Timer // Pending save of a resize (see scheduleLayoutSave)
+
+	// --- Clipboard State ---
+	clipboards []ClipboardBackend // Sinks tried in order when copying
+
+	// --- Split State ---
+	splitLimit  SplitLimit   // Size above which CopyAllSelected copies in parts
+	bundleParts []bundlePart // Parts of the last split copy, nil if not split
+	bundlePart  int          // Index of the part last put on the clipboard
+	partsKey    string       // bundleStateKey when bundleParts were made
+
 	// --- Copy Highlight State ---
 	isCopyHighlightActive bool
+
+	// --- Status Bar State ---
+	statusMessage string // Message shown instead of the counts until resetStatus
 }
 
-// NewApp creates a new application instance.
-func NewApp(rootDir string) *App {
+// NewApp creates a new application instance for one or more absolute root directories.
+func NewApp(rootDirs []string) *App {
 	tke, _ := tiktoken.GetEncoding("cl100k_base")
 
 	app := &App{
-		rootDir:                rootDir,
+		roots:                  newRoots(rootDirs),
 		selectedFiles:          make(map[string]bool),
-		gitignoreMatcher:       nil,
 		fileList:               []string{},
 		allFiles:               []string{},
 		currentLine:            0,
@@ -102,7 +367,16 @@ func NewApp(rootDir string) *App {
 		tokenizer:              tke,
 		currentlyPreviewedFile: "", // Initialize live preview field
 		contentViewOriginY:     0,  // Initialize content view scroll
+		lineRanges:             make(map[string][]LineRange),
+		renderModes:            make(map[string]RenderMode),
+		truncations:            make(map[string]Truncation),
+		theme:                  builtinThemes["default"],
+		importDepth:            DefaultImportDepth,
+		orderStrategy:          OrderAlphabetical,
+		layout:                 PaneLayout{Mode: LayoutAuto},
+		stackWidth:             DefaultStackWidth,
 		cache:                  make(AppCache),
+		cacheKey:               sessionCacheKey(rootDirs),
 
 		// --- Initialize Cache View State ---
 		showCacheView:                  false,
@@ -110,11 +384,25 @@ func NewApp(rootDir string) *App {
 		cacheViewOriginY:               0,
 		awaitingCacheClearConfirmation: false,
 
+		// --- Initialize Skipped Files State ---
+		skippedFiles:  make(map[string]SkippedFile),
+		filterSkipped: make(map[string]SkippedFile),
+		forceIncluded: make(map[string]bool),
+		expandedDirs:  make(map[string]bool),
+
+		// --- Initialize Symlink State ---
+		symlinkPolicy:  SymlinkFollow,
+		symlinkedFiles: make(map[string]string),
+		listedSymlinks: make(map[string]string),
+
 		// --- Initialize Loading State ---
 		isLoading:     true,
 		loadingError:  nil,
 		loadStartTime: time.Now(),
 
+		// --- Initialize Clipboard State ---
+		clipboards: []ClipboardBackend{nativeClipboard{}}, // See SetClipboards
+
 		// --- Initialize Copy Highlight State ---
 		isCopyHighlightActive: false,
 	}
@@ -130,16 +418,43 @@ func NewApp(rootDir string) *App {
 			app.cache = make(AppCache)
 		}
 
-		// Load settings for the current directory from cache if available
-		if entry, ok := app.cache[app.rootDir]; ok {
+		// Load settings for the current directory (or set of roots) from cache if available
+		if entry, ok := app.cache[app.cacheKey]; ok {
 			app.includes = entry.Includes
 			app.excludes = entry.Excludes
 			app.filterMode = entry.FilterMode
+			for _, path := range entry.ForceIncludes {
+				app.forceIncluded[path] = true
+			}
+			app.lineNumbers = entry.LineNumbers
+			app.transforms = entry.Transforms
+			app.instruction = entry.Instruction
+			app.instructionAfter = entry.InstructionAfter
+			app.pinnedFiles = entry.Pinned
+			for relPath, spec := range entry.Truncations {
+				if t, err := ParseTruncation(spec); err == nil {
+					app.truncations[relPath] = t
+				}
+			}
+			if strategy, err := ParseOrderStrategy(string(entry.OrderStrategy)); err == nil {
+				app.orderStrategy = strategy
+			}
+			if entry.SymlinkPolicy != "" {
+				app.symlinkPolicy = entry.SymlinkPolicy
+			}
+			if entry.Layout != nil {
+				app.layout = *entry.Layout
+				if mode, err := ParseLayoutMode(string(entry.Layout.Mode)); err == nil {
+					app.layout.Mode = mode
+				} else {
+					app.layout.Mode = LayoutAuto
+				}
+			}
 			entry.LastOpened = time.Now()
-			app.cache[app.rootDir] = entry
+			app.cache[app.cacheKey] = entry
 		} else {
 			// Only add if not found, keep existing defaults otherwise
-			app.cache[app.rootDir] = DirectoryCache{
+			app.cache[app.cacheKey] = DirectoryCache{
 				Includes:   app.includes,
 				Excludes:   app.excludes,
 				LastOpened: time.Now(),
@@ -162,9 +477,13 @@ func (app *App) SetGui(g *gocui.Gui) {
 	app.g = g
 }
 
-// RootDir returns the root directory being scanned.
-func (app *App) RootDir() string {
-	return app.rootDir
+// RootDirs returns the root directories being scanned.
+func (app *App) RootDirs() []string {
+	dirs := make([]string, len(app.roots))
+	for i, root := range app.roots {
+		dirs[i] = root.Dir
+	}
+	return dirs
 }
 
 // FileList returns the currently filtered list of files.
@@ -173,8 +492,137 @@ func (app *App) FileList() []string {
 	return app.fileList
 }
 
-func (app *App) SetGitignoreMatcher(matcher gitignore.GitIgnore) {
+// SetGitignoreMatcher assigns the .gitignore matcher for the root at rootDir.
+func (app *App) SetGitignoreMatcher(rootDir string, matcher gitignore.GitIgnore) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	for i := range app.roots {
+		if app.roots[i].Dir == rootDir {
+			app.roots[i].gitignoreMatcher = matcher
+		}
+	}
+}
+
+// SymlinkPolicy returns the active symlink policy.
+func (app *App) SymlinkPolicy() SymlinkPolicy {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	return app.symlinkPolicy
+}
+
+// SetSymlinkPolicy sets how symlinks are treated by ListFiles and remembers the
+// policy for this directory. Must be called before ListFiles.
+func (app *App) SetSymlinkPolicy(policy SymlinkPolicy, allowEscape bool) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.symlinkPolicy = policy
+	app.allowSymlinkEscape = allowEscape
+
+	if app.cacheFilePath != "" {
+		err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+			entry.SymlinkPolicy = policy
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not save cache file %s: %v\n", app.cacheFilePath, err)
+		}
+	}
+}
+
+// SetStatusMessage sets a status bar message to show once the file browser is
+// displayed (e.g. the --select-from report). The counts return after a few seconds.
+func (app *App) SetStatusMessage(message string) {
+	app.mutex.Lock()
+	app.statusMessage = message
+	g := app.g
+	app.mutex.Unlock()
+
+	if g == nil {
+		return
+	}
+	time.AfterFunc(5*time.Second, func() {
+		app.mutex.Lock()
+		unchanged := app.statusMessage == message
+		app.mutex.Unlock()
+		if unchanged {
+			app.resetStatus(g)
+		}
+	})
+}
+
+// SetLineNumbers enables or disables line numbers in the bundle for this
+// session (e.g. from --line-numbers), without changing the cached setting.
+func (app *App) SetLineNumbers(enabled bool) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.lineNumbers = enabled
+}
+
+// SetClipboards sets the sinks tried in order when copying (see
+// NewClipboardChain).
+func (app *App) SetClipboards(chain []ClipboardBackend) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.clipboards = chain
+}
+
+// SetSplitLimit sets the part size above which bundles are copied in parts.
+func (app *App) SetSplitLimit(limit SplitLimit) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.splitLimit = limit
+}
+
+// SetImportDepth sets how many levels of in-module imports ExpandGoImports
+// follows.
+func (app *App) SetImportDepth(depth int) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.importDepth = depth
+}
+
+// SetOrderStrategy sets the order strategy for this session (e.g. from
+// --order), without changing the cached setting.
+func (app *App) SetOrderStrategy(strategy OrderStrategy) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.orderStrategy = strategy
+}
+
+// SetHighlighter sets the syntax highlighter of the Content view, nil to
+// show files uncoloured.
+func (app *App) SetHighlighter(h *Highlighter) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.highlighter = h
+}
+
+// SetTheme sets the colours of the UI. It must be called before the UI
+// starts: layouts read the theme without locking.
+func (app *App) SetTheme(theme Theme) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.theme = theme
+}
+
+// SetStackWidth sets the terminal width below which the auto layout stacks
+// the Files and Content panes.
+func (app *App) SetStackWidth(width int) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.stackWidth = width
+}
+
+// SetTruncation sets the truncation rule for files without an override.
+func (app *App) SetTruncation(t Truncation) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.truncation = t
+}
+
+// SetTransforms sets the content transforms for this session (e.g. from
+// --transforms), without changing the cached setting.
+func (app *App) SetTransforms(transforms Transforms) {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
-	app.gitignoreMatcher = matcher
+	app.transforms = transforms
 }
@@ -0,0 +1,364 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bundleSection is one FILE block of the bundle.
+type bundleSection struct {
+	header     string // Text after "FILE: "
+	content    string
+	truncated  bool // Lines were cut by the file's truncation rule
+	redactions int  // Secrets replaced by placeholders in content
+}
+
+// fileSections returns the blocks bundled for relPath: the whole file, its Go
+// skeleton, or one block per marked line range when the file is partially
+// selected. Content transforms, redaction (see redactLines), truncation and
+// line numbers are applied here, in that order, so sizes and token counts
+// match the copied bundle. Marked line ranges are never truncated.
+func (app *App) fileSections(relPath string) ([]bundleSection, error) {
+	fileContent, err := app.readFileContent(relPath)
+	if err != nil {
+		return nil, err
+	}
+
+	app.mutex.Lock()
+	ranges := append([]LineRange(nil), app.lineRanges[relPath]...)
+	lineNumbers := app.lineNumbers
+	transforms := app.transforms
+	mode := app.renderModes[relPath]
+	truncation := app.truncationFor(relPath)
+	app.mutex.Unlock()
+
+	measure := app.sizer()
+	countTokens := func(text string) int { return measure(text).tokens }
+
+	if mode == RenderSkeleton {
+		// Line ranges and numbers refer to the full file, so they don't apply.
+		header, source := relPath+" (skeleton)", string(fileContent)
+		if skeleton, err := goSkeleton(relPath, fileContent); err != nil {
+			header = fmt.Sprintf("%s (full: skeleton failed: %v)", relPath, err)
+		} else {
+			source = skeleton
+		}
+		lines, redactions := redactLines(relPath, transformLines(relPath, source, transforms))
+		lines, truncated := truncateLines(lines, truncation, countTokens)
+		if truncated {
+			header = strings.TrimSuffix(header, ")") + ", truncated: " + truncation.Label() + ")"
+		}
+		return []bundleSection{{header: header, content: renderLines(lines, false, 0), truncated: truncated, redactions: redactions}}, nil
+	}
+
+	if len(ranges) == 0 && !lineNumbers && !transforms.Any() && !truncation.Active() {
+		content, redactions := redactSecrets(relPath, string(fileContent))
+		return []bundleSection{{header: relPath, content: content, redactions: redactions}}, nil
+	}
+
+	source := transformLines(relPath, string(fileContent), transforms)
+	lines, redactions := redactLines(relPath, source)
+	width := gutterWidth(len(splitLines(string(fileContent))))
+
+	if len(ranges) == 0 {
+		lines, truncated := truncateLines(lines, truncation, countTokens)
+		header := relPath
+		if truncated {
+			header = fmt.Sprintf("%s (truncated: %s)", relPath, truncation.Label())
+		}
+		return []bundleSection{{header: header, content: renderLines(lines, lineNumbers, width), truncated: truncated, redactions: redactions}}, nil
+	}
+
+	// Headers give the numbers of the first and last lines kept, in the
+	// original file: with transforms they no longer count bundled lines.
+	rangeLabel := "lines"
+	if transforms.Any() {
+		rangeLabel = "source lines"
+	}
+	sections := make([]bundleSection, 0, len(ranges))
+	for _, r := range ranges {
+		inRange, inSource := linesIn(lines, r), linesIn(source, r)
+		if len(inRange) == 0 {
+			continue // Range no longer exists (file shrank) or was transformed away
+		}
+		redactions := countPlaceholders(inRange) - countPlaceholders(inSource)
+		if redactions == 0 && len(inRange) < len(inSource) {
+			redactions = 1 // The range holds only the rest of a multi-line secret
+		}
+		sections = append(sections, bundleSection{
+			header:     fmt.Sprintf("%s (%s %d-%d)", relPath, rangeLabel, inRange[0].no, inRange[len(inRange)-1].no),
+			content:    renderLines(inRange, lineNumbers, width),
+			redactions: redactions,
+		})
+	}
+	return sections, nil
+}
+
+// linesIn returns the lines numbered within r.
+func linesIn(lines []numberedLine, r LineRange) []numberedLine {
+	var inRange []numberedLine
+	for _, line := range lines {
+		if line.no >= r.Start && line.no <= r.End {
+			inRange = append(inRange, line)
+		}
+	}
+	return inRange
+}
+
+// countPlaceholders counts the redaction placeholders in lines.
+func countPlaceholders(lines []numberedLine) int {
+	count := 0
+	for _, line := range lines {
+		count += strings.Count(line.text, "[REDACTED:")
+	}
+	return count
+}
+
+// gutterWidth returns the width of the line number gutter for a file with
+// lineCount lines, so every line of a file uses the same width.
+func gutterWidth(lineCount int) int {
+	return len(strconv.Itoa(max(1, lineCount)))
+}
+
+// renderLines joins lines into bundle text, prefixing each with its original
+// line number in a right-aligned gutter of the given width when numbered.
+// Lines without a number (truncation markers) get an empty gutter.
+func renderLines(lines []numberedLine, numbered bool, width int) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if numbered && line.no == 0 {
+			fmt.Fprintf(&b, "%*s | ", width, "")
+		} else if numbered {
+			fmt.Fprintf(&b, "%*d | ", width, line.no)
+		}
+		b.WriteString(line.text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// splitLines splits content into lines, without a trailing empty line for a
+// final newline.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// addLineRange adds r to the ranges of relPath, merging overlapping or adjacent
+// ranges. Assumes mutex is held.
+func (app *App) addLineRange(relPath string, r LineRange) {
+	ranges := append(app.lineRanges[relPath], r)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+
+	merged := ranges[:1]
+	for _, next := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if next.Start <= last.End+1 {
+			last.End = max(last.End, next.End)
+		} else {
+			merged = append(merged, next)
+		}
+	}
+	app.lineRanges[relPath] = merged
+}
+
+// bundle is the text copied to the clipboard and what went into it.
+type bundle struct {
+	content  string
+	files    int           // Number of files it contains
+	entries  []bundleEntry // Bundled content per file, in order
+	redacted []string      // Files in which secrets were replaced by placeholders
+}
+
+// bundleEntry is the redacted content bundled for one file, with its sections
+// joined. err is set if the file could not be read.
+type bundleEntry struct {
+	path    string
+	content string
+	block   string // The entry as it appears in the bundle, separators included
+	err     error
+}
+
+// buildBundle concatenates the given files into the text that is copied to the
+// clipboard, each preceded by a FILE separator. Likely secrets are redacted
+// (see fileSections).
+func (app *App) buildBundle(paths []string) bundle {
+	var contentBuilder strings.Builder
+	var result bundle
+
+	for _, relPath := range paths {
+		sections, err := app.fileSections(relPath)
+		if err != nil {
+			block := fileSeparator(relPath) + fmt.Sprintf("\n!!! ERROR READING FILE: %v !!!\n\n", err)
+			contentBuilder.WriteString(block)
+			result.entries = append(result.entries, bundleEntry{path: relPath, block: block, err: err})
+			result.files++
+			continue
+		}
+
+		redactions := 0
+		var entryContent, blockBuilder strings.Builder
+		for _, section := range sections {
+			content := section.content
+			redactions += section.redactions
+			entryContent.WriteString(content)
+
+			blockBuilder.WriteString(fileSeparator(section.header))
+			blockBuilder.WriteString("\n")
+			blockBuilder.WriteString(content)
+			if !strings.HasSuffix(content, "\n") {
+				blockBuilder.WriteString("\n")
+			}
+			blockBuilder.WriteString("\n")
+		}
+		if redactions > 0 {
+			result.redacted = append(result.redacted, relPath)
+		}
+		contentBuilder.WriteString(blockBuilder.String())
+		result.entries = append(result.entries, bundleEntry{path: relPath, content: entryContent.String(), block: blockBuilder.String()})
+		result.files++
+	}
+
+	result.content = contentBuilder.String()
+	return result
+}
+
+// fileSeparator is the banner introducing a file section in a bundle.
+func fileSeparator(header string) string {
+	return fmt.Sprintf("==========================\nFILE: %s\n==========================\n", header)
+}
+
+// fileSize is the bundled size of a file, cached by bundleSize.
+type fileSize struct {
+	key           string // sizeKey the size was measured for
+	chars, tokens int
+}
+
+// bundleSize returns the characters (bytes) and tokens the given files add to
+// the bundle, and how many of them could not be read. Tokens are 0 when the
+// tokenizer is unavailable. Sizes are cached per file until its render state
+// or the file itself changes (see sizeKey).
+func (app *App) bundleSize(paths []string) (chars, tokens, readErrors int) {
+	app.mutex.Lock()
+	tokenizer := app.tokenizer
+	app.mutex.Unlock()
+
+	for _, relPath := range paths {
+		key, err := app.sizeKey(relPath)
+		if err != nil {
+			readErrors++
+			continue
+		}
+		app.mutex.Lock()
+		cached, ok := app.fileSizes[relPath]
+		app.mutex.Unlock()
+		if ok && cached.key == key {
+			chars += cached.chars
+			tokens += cached.tokens
+			continue
+		}
+
+		sections, err := app.fileSections(relPath)
+		if err != nil {
+			readErrors++
+			continue
+		}
+		size := fileSize{key: key}
+		for _, section := range sections {
+			size.chars += len(section.content)
+			if tokenizer != nil {
+				size.tokens += len(tokenizer.Encode(section.content, nil, nil))
+			}
+		}
+		chars += size.chars
+		tokens += size.tokens
+
+		app.mutex.Lock()
+		if app.fileSizes == nil {
+			app.fileSizes = make(map[string]fileSize)
+		}
+		app.fileSizes[relPath] = size
+		app.mutex.Unlock()
+	}
+	return chars, tokens, readErrors
+}
+
+// sizeKey describes everything the bundled content of relPath depends on: its
+// ranges, render mode and truncation, the bundle options, and the file's
+// modification time and size.
+func (app *App) sizeKey(relPath string) (string, error) {
+	app.mutex.Lock()
+	state := fmt.Sprintf("%v %v %+v %v %s %v", app.lineRanges[relPath], app.lineNumbers, app.transforms,
+		app.renderModes[relPath], app.truncationFor(relPath), app.tokenizer != nil)
+	target, isListedLink := app.listedSymlinks[relPath]
+	fullPath, ok := app.resolvePath(relPath)
+	app.mutex.Unlock()
+
+	if isListedLink {
+		return state + " -> " + target, nil // Bundled as its target, see readFileContent
+	}
+	if !ok {
+		return "", fmt.Errorf("no root directory for %s", relPath)
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %d %d", state, info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// WriteBundle writes the bundle for the current selection to w, for headless
+// use. When nothing is selected, every visible (filtered) file except
+// sensitive ones (see isSensitivePath) is bundled. With a non-empty
+// templateName the bundle is rendered through that prompt template.
+// It returns the number of files written and the files that had redactions.
+func (app *App) WriteBundle(w io.Writer, templateName string) (int, []string, error) {
+	paths := app.selectedInOrder()
+	if len(paths) == 0 {
+		app.mutex.Lock()
+		for _, relPath := range app.fileList {
+			if !isSensitivePath(relPath) { // Only included when selected explicitly
+				paths = append(paths, relPath)
+			}
+		}
+		app.mutex.Unlock()
+	}
+
+	result := app.buildBundle(paths)
+	text := app.withInstruction(result.content)
+	if templateName != "" {
+		var chosen *promptTemplate
+		for _, t := range app.findPromptTemplates() {
+			if t.name == templateName {
+				chosen = &t
+				break
+			}
+		}
+		if chosen == nil {
+			return 0, nil, fmt.Errorf("template %q not found", templateName)
+		}
+		var err error
+		if text, err = app.renderPromptTemplate(*chosen, result); err != nil {
+			return 0, nil, fmt.Errorf("template %s: %w", templateName, err)
+		}
+	}
+
+	_, err := io.WriteString(w, text)
+	return result.files, result.redacted, err
+}
+
+// lineInRanges reports whether the 1-based lineNo falls inside any of ranges.
+func lineInRanges(lineNo int, ranges []LineRange) bool {
+	for _, r := range ranges {
+		if lineNo >= r.Start && lineNo <= r.End {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newTestApp returns an App rooted at a temporary directory holding files,
+// without a tokenizer or cache.
+func newTestApp(t *testing.T, files map[string]string) *App {
+	t.Helper()
+	dir := t.TempDir()
+	writeTree(t, dir, files)
+	return &App{
+		roots:          newRoots([]string{dir}),
+		selectedFiles:  make(map[string]bool),
+		lineRanges:     make(map[string][]LineRange),
+		renderModes:    make(map[string]RenderMode),
+		truncations:    make(map[string]Truncation),
+		listedSymlinks: make(map[string]string),
+	}
+}
+
+func TestFileSectionsRangeHeaders(t *testing.T) {
+	source := strings.Join([]string{
+		"package main", // 1
+		"",             // 2
+		"// comment",   // 3
+		"// comment",   // 4
+		"func a() {}",  // 5
+		"func b() {}",  // 6
+		"// comment",   // 7
+	}, "\n") + "\n"
+
+	tests := []struct {
+		name       string
+		ranges     []LineRange
+		transforms Transforms
+		want       []string
+	}{
+		{"plain", []LineRange{{1, 2}, {5, 6}}, Transforms{}, []string{"main.go (lines 1-2)", "main.go (lines 5-6)"}},
+		{"range past the end", []LineRange{{6, 20}}, Transforms{}, []string{"main.go (lines 6-7)"}},
+		{"leading lines removed", []LineRange{{3, 6}}, Transforms{StripComments: true}, []string{"main.go (source lines 5-6)"}},
+		{"range removed", []LineRange{{3, 4}, {7, 7}}, Transforms{StripComments: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, map[string]string{"main.go": source})
+			app.lineRanges["main.go"] = tt.ranges
+			app.transforms = tt.transforms
+
+			sections, err := app.fileSections("main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var headers []string
+			for _, section := range sections {
+				headers = append(headers, section.header)
+			}
+			if strings.Join(headers, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("headers = %q, want %q", headers, tt.want)
+			}
+		})
+	}
+}
+
+// TestBundleSizeCache checks that sizes are reused until the render state or
+// the file changes.
+func TestBundleSizeCache(t *testing.T) {
+	app := newTestApp(t, map[string]string{"a.go": "package a\n"})
+	size := func() int {
+		chars, _, readErrors := app.bundleSize([]string{"a.go"})
+		if readErrors != 0 {
+			t.Fatalf("%d read errors", readErrors)
+		}
+		return chars
+	}
+
+	if got := size(); got != len("package a\n") {
+		t.Fatalf("size = %d, want %d", got, len("package a\n"))
+	}
+	cached := app.fileSizes["a.go"]
+	cached.chars = -1 // Returned only if the cache is used
+	app.fileSizes["a.go"] = cached
+	if got := size(); got != -1 {
+		t.Errorf("size = %d, want the cached size", got)
+	}
+
+	app.lineNumbers = true
+	if got := size(); got != len("1 | package a\n") {
+		t.Errorf("size with line numbers = %d, want %d", got, len("1 | package a\n"))
+	}
+
+	absPath, _ := app.resolvePath("a.go")
+	if err := os.WriteFile(absPath, []byte("package a\n\nfunc A() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := size(); got != len("1 | package a\n2 | \n3 | func A() {}\n") {
+		t.Errorf("size after an edit = %d, want %d", got, len("1 | package a\n2 | \n3 | func A() {}\n"))
+	}
+}
+
+func TestLineNumbers(t *testing.T) {
+	var source strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&source, "line %d\n", i)
+	}
+	app := newTestApp(t, map[string]string{"a.txt": source.String()})
+	app.lineNumbers = true
+
+	sections, err := app.fileSections("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sections[0].content; !strings.HasPrefix(got, " 1 | line 1\n 2 | line 2\n") || !strings.HasSuffix(got, "10 | line 10\n") {
+		t.Errorf("content = %q, want a two-column gutter", got)
+	}
+
+	// Ranges keep the file's numbers and gutter width.
+	app.lineRanges["a.txt"] = []LineRange{{2, 3}}
+	if sections, err = app.fileSections("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sections[0].content; got != " 2 | line 2\n 3 | line 3\n" {
+		t.Errorf("range content = %q", got)
+	}
+
+	lines := []numberedLine{{no: 9, text: "a"}, {text: "... cut ..."}, {no: 120, text: "b"}}
+	if got := renderLines(lines, true, gutterWidth(120)); got != "  9 | a\n    | ... cut ...\n120 | b\n" {
+		t.Errorf("renderLines = %q, want markers with an empty gutter", got)
+	}
+	if got := renderLines(lines, false, 3); got != "a\n... cut ...\nb\n" {
+		t.Errorf("renderLines without numbers = %q", got)
+	}
+}
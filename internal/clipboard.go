@@ -0,0 +1,330 @@
+package internal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// Clipboard backend names, as used by --clipboard and the config file.
+const (
+	ClipboardAuto    = "auto"    // The default fallback order (see NewClipboardChain)
+	ClipboardNative  = "native"  // xclip/xsel/wl-copy, pbcopy or the Windows clipboard
+	ClipboardOSC52   = "osc52"   // Terminal escape sequence, works over SSH and in tmux
+	ClipboardTmux    = "tmux"    // tmux paste buffer (tmux load-buffer)
+	ClipboardCommand = "command" // Pipe to a user command, e.g. "pbcopy" or "ssh host xclip"
+	ClipboardFile    = "file"    // Write to a file, the last resort
+)
+
+// DefaultClipboardFile returns where the file sink writes unless configured:
+// grepforllm-bundle.md in $XDG_RUNTIME_DIR, or grepforllm/bundle.md in the
+// user's cache directory. Unlike /tmp, both are private to the user.
+func DefaultClipboardFile() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "grepforllm-bundle.md"), nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for the clipboard file: %w", err)
+	}
+	return filepath.Join(cacheDir, "grepforllm", "bundle.md"), nil
+}
+
+// osc52ChunkSize is how many bytes are written to the terminal at a time.
+const osc52ChunkSize = 4096
+
+// screenChunkSize is the longest string GNU screen accepts in one DCS passthrough.
+const screenChunkSize = 76
+
+// ClipboardBackend puts text on a clipboard, or another sink standing in for it.
+type ClipboardBackend interface {
+	Name() string // Shown in the status bar, e.g. "tmux buffer"
+	Write(text string) error
+}
+
+// ClipboardConfig selects and configures the clipboard sinks.
+type ClipboardConfig struct {
+	Backend  string   // Preferred sink, tried first (ClipboardAuto for the default order)
+	Fallback []string // Sinks tried in order after Backend; nil for the default order
+	File     string   // Path for ClipboardFile (DefaultClipboardFile() if empty)
+	Command  string   // Shell command for ClipboardCommand; the sink is skipped if empty
+}
+
+// NewClipboardChain returns the sinks to try in order when copying. The
+// default order is native, tmux, osc52, command, file; sinks that cannot work
+// here (no clipboard tool, no terminal, not in tmux, no command) are left out
+// unless named explicitly as the preferred backend.
+func NewClipboardChain(cfg ClipboardConfig) ([]ClipboardBackend, error) {
+	order := cfg.Fallback
+	if order == nil {
+		// tmux comes before OSC 52: load-buffer reports failure, while an
+		// OSC 52 sequence tmux does not pass through is silently dropped.
+		order = []string{ClipboardNative, ClipboardTmux, ClipboardOSC52, ClipboardCommand, ClipboardFile}
+	}
+
+	preferred := strings.ToLower(strings.TrimSpace(cfg.Backend))
+	if preferred == ClipboardAuto {
+		preferred = ""
+	}
+
+	var chain []ClipboardBackend
+	seen := make(map[string]bool)
+	add := func(name string, explicit bool) error {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+
+		switch name {
+		case ClipboardNative:
+			if explicit || nativeClipboardUsable() {
+				chain = append(chain, nativeClipboard{})
+			}
+		case ClipboardOSC52:
+			if explicit || terminalAvailable() {
+				chain = append(chain, newOSC52Clipboard())
+			}
+		case ClipboardTmux:
+			if explicit || os.Getenv("TMUX") != "" {
+				chain = append(chain, tmuxClipboard{})
+			}
+		case ClipboardCommand:
+			if cfg.Command != "" {
+				chain = append(chain, commandClipboard{command: cfg.Command})
+			} else if explicit {
+				return fmt.Errorf("clipboard backend %q needs a command (--clipboard-command or \"clipboardCommand\")", name)
+			}
+		case ClipboardFile:
+			path := cfg.File
+			if path == "" {
+				var err error
+				if path, err = DefaultClipboardFile(); err != nil {
+					if explicit {
+						return err
+					}
+					return nil
+				}
+			}
+			// Configured explicitly, the file is written even after an
+			// unverified sink (see copyWithFallback).
+			chain = append(chain, fileClipboard{path: path, explicit: explicit || cfg.File != "" || cfg.Fallback != nil})
+		default:
+			return fmt.Errorf("invalid clipboard backend %q (expected auto, native, osc52, tmux, command or file)", name)
+		}
+		return nil
+	}
+
+	if preferred != "" {
+		if err := add(preferred, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range order {
+		if err := add(name, false); err != nil {
+			return nil, err
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no usable clipboard backend in %v", order)
+	}
+	return chain, nil
+}
+
+// unverifiedBackend is implemented by sinks that cannot tell whether the text
+// arrived, such as OSC 52: terminals ignore sequences they don't support.
+type unverifiedBackend interface {
+	Unverified() bool
+}
+
+// isUnverified reports whether a successful write to backend may still have
+// gone nowhere.
+func isUnverified(backend ClipboardBackend) bool {
+	u, ok := backend.(unverifiedBackend)
+	return ok && u.Unverified()
+}
+
+// standInBackend is implemented by sinks that only stand in for a clipboard,
+// such as a file nobody asked for: once an unverified sink took the text they
+// are skipped.
+type standInBackend interface {
+	StandIn() bool
+}
+
+// isStandIn reports whether backend is only worth writing when no other sink
+// took the text.
+func isStandIn(backend ClipboardBackend) bool {
+	s, ok := backend.(standInBackend)
+	return ok && s.StandIn()
+}
+
+// copyWithFallback writes text to the sinks in chain until one accepts it and
+// returns the sinks that accepted it, along with the errors of the sinks that
+// failed. Sinks that cannot confirm delivery don't stop the chain, so the
+// next working sink also gets the text, unless it only stands in for a
+// clipboard (see standInBackend).
+func copyWithFallback(chain []ClipboardBackend, text string) ([]ClipboardBackend, []error) {
+	var used []ClipboardBackend
+	var failures []error
+	for _, backend := range chain {
+		if len(used) > 0 && isStandIn(backend) {
+			continue // An unverified sink already took the text
+		}
+		if err := backend.Write(text); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", backend.Name(), err))
+			continue
+		}
+		used = append(used, backend)
+		if !isUnverified(backend) {
+			break
+		}
+	}
+	return used, failures
+}
+
+// nativeClipboardUsable reports whether the system clipboard can be reached:
+// a clipboard tool exists and, over SSH on Linux, there is a display to use.
+func nativeClipboardUsable() bool {
+	if clipboard.Unsupported {
+		return false
+	}
+	overSSH := os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+	noDisplay := os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+	return !(overSSH && noDisplay)
+}
+
+// terminalAvailable reports whether there is a controlling terminal to send
+// OSC 52 sequences to.
+func terminalAvailable() bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// nativeClipboard uses the platform clipboard through atotto/clipboard.
+type nativeClipboard struct{}
+
+func (nativeClipboard) Name() string { return "system clipboard" }
+
+func (nativeClipboard) Write(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// osc52Clipboard asks the terminal to set its clipboard with an OSC 52 escape
+// sequence, wrapped for tmux or GNU screen when running inside them.
+type osc52Clipboard struct {
+	tty    string // Terminal device the sequence is written to
+	tmux   bool
+	screen bool
+}
+
+func newOSC52Clipboard() osc52Clipboard {
+	return osc52Clipboard{
+		tty:    "/dev/tty",
+		tmux:   os.Getenv("TMUX") != "",
+		screen: os.Getenv("STY") != "",
+	}
+}
+
+func (c osc52Clipboard) Name() string { return "OSC 52" }
+
+func (c osc52Clipboard) Unverified() bool { return true }
+
+func (c osc52Clipboard) Write(text string) error {
+	out, err := os.OpenFile(c.tty, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("open terminal: %w", err)
+	}
+	defer out.Close()
+	return writeChunked(out, c.sequence(text))
+}
+
+// sequence builds the escape sequence setting the clipboard to text.
+func (c osc52Clipboard) sequence(text string) string {
+	osc := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case c.tmux:
+		// tmux passes DCS "tmux;" payloads through, with ESC doubled.
+		// Requires "set -g allow-passthrough on" in tmux 3.3 and later.
+		return "\x1bPtmux;" + strings.ReplaceAll(osc, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case c.screen:
+		// screen limits the length of a DCS string, so split the sequence.
+		var b strings.Builder
+		for len(osc) > 0 {
+			n := min(screenChunkSize, len(osc))
+			b.WriteString("\x1bP" + osc[:n] + "\x1b\\")
+			osc = osc[n:]
+		}
+		return b.String()
+	default:
+		return osc
+	}
+}
+
+// writeChunked writes s in osc52ChunkSize pieces, so large payloads don't
+// overflow the terminal's input buffer in a single write.
+func writeChunked(w io.Writer, s string) error {
+	for len(s) > 0 {
+		n := min(osc52ChunkSize, len(s))
+		if _, err := io.WriteString(w, s[:n]); err != nil {
+			return err
+		}
+		s = s[n:]
+	}
+	return nil
+}
+
+// tmuxClipboard loads the text into the tmux paste buffer (prefix + ] pastes).
+type tmuxClipboard struct{}
+
+func (tmuxClipboard) Name() string { return "tmux buffer" }
+
+func (tmuxClipboard) Write(text string) error {
+	cmd := exec.Command("tmux", "load-buffer", "-")
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// commandClipboard pipes the text to a shell command.
+type commandClipboard struct {
+	command string
+}
+
+func (c commandClipboard) Name() string { return fmt.Sprintf("command %q", c.command) }
+
+func (c commandClipboard) Write(text string) error {
+	cmd := exec.Command("sh", "-c", c.command)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// fileClipboard writes the text to a file, readable only by the user.
+type fileClipboard struct {
+	path     string
+	explicit bool // Configured rather than part of the default order
+}
+
+func (c fileClipboard) Name() string  { return "file " + c.path }
+func (c fileClipboard) StandIn() bool { return !c.explicit }
+
+func (c fileClipboard) Write(text string) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, []byte(text), 0o600)
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeClipboard is a sink that fails or succeeds as told.
+type fakeClipboard struct {
+	name       string
+	fail       bool
+	unverified bool
+	standIn    bool
+}
+
+func (c fakeClipboard) Name() string     { return c.name }
+func (c fakeClipboard) Unverified() bool { return c.unverified }
+func (c fakeClipboard) StandIn() bool    { return c.standIn }
+
+func (c fakeClipboard) Write(text string) error {
+	if c.fail {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestCopyWithFallback(t *testing.T) {
+	native := fakeClipboard{name: "native"}
+	broken := fakeClipboard{name: "broken", fail: true}
+	osc52 := fakeClipboard{name: "osc52", unverified: true}
+	command := fakeClipboard{name: "command"}
+	file := fakeClipboard{name: "file", standIn: true}
+	configuredFile := fakeClipboard{name: "configured file"}
+
+	tests := []struct {
+		name     string
+		chain    []ClipboardBackend
+		used     string
+		failures int
+	}{
+		{"first works", []ClipboardBackend{native, file}, "native", 0},
+		{"falls back", []ClipboardBackend{broken, native, file}, "native", 1},
+		{"unverified keeps going", []ClipboardBackend{osc52, command, file}, "osc52 command", 0},
+		{"no stand-in after unverified", []ClipboardBackend{osc52, file}, "osc52", 0},
+		{"configured file after unverified", []ClipboardBackend{osc52, configuredFile}, "osc52 configured file", 0},
+		{"stand-in as last resort", []ClipboardBackend{broken, file}, "file", 1},
+		{"unverified last", []ClipboardBackend{broken, osc52}, "osc52", 1},
+		{"all fail", []ClipboardBackend{broken}, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, failures := copyWithFallback(tt.chain, "text")
+			var names []string
+			for _, backend := range used {
+				names = append(names, backend.Name())
+			}
+			if got := strings.Join(names, " "); got != tt.used || len(failures) != tt.failures {
+				t.Errorf("used %q with %d failure(s), want %q with %d", got, len(failures), tt.used, tt.failures)
+			}
+		})
+	}
+}
+
+func TestNewClipboardChainTmuxFirst(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	chain, err := NewClipboardChain(ClipboardConfig{Backend: ClipboardAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	position := make(map[string]int)
+	for i, backend := range chain {
+		position[backend.Name()] = i
+	}
+	tmux, hasTmux := position["tmux buffer"]
+	osc52, hasOSC52 := position["OSC 52"]
+	if !hasTmux || (hasOSC52 && osc52 < tmux) {
+		t.Errorf("the tmux buffer should come before OSC 52 in %v", position)
+	}
+}
+
+func TestDefaultClipboardFile(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if path, err := DefaultClipboardFile(); err != nil || path != "/run/user/1000/grepforllm-bundle.md" {
+		t.Errorf("DefaultClipboardFile() = %q, %v with XDG_RUNTIME_DIR set", path, err)
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("XDG_CACHE_HOME", "/home/me/.cache")
+	t.Setenv("HOME", "/home/me")
+	if path, err := DefaultClipboardFile(); err != nil || strings.HasPrefix(path, os.TempDir()) || filepath.Base(path) != "bundle.md" {
+		t.Errorf("DefaultClipboardFile() = %q, %v; want a file in the user cache directory", path, err)
+	}
+}
+
+func TestNewClipboardChainFileStandIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ClipboardConfig
+		standIn bool
+	}{
+		{"default order", ClipboardConfig{Backend: ClipboardAuto}, true},
+		{"preferred", ClipboardConfig{Backend: ClipboardFile}, false},
+		{"path configured", ClipboardConfig{File: "/home/me/bundle.md"}, false},
+		{"fallback configured", ClipboardConfig{Fallback: []string{ClipboardFile}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+			chain, err := NewClipboardChain(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, backend := range chain {
+				if file, ok := backend.(fileClipboard); ok && file.StandIn() != tt.standIn {
+					t.Errorf("%s stands in: %v, want %v", file.Name(), file.StandIn(), tt.standIn)
+				}
+			}
+		})
+	}
+}
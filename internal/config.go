@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config holds user settings from ~/.config/grepforllm/config.json. Command
+// line flags override them.
+type Config struct {
+	// Clipboard selects the preferred clipboard backend: auto (default),
+	// native, osc52, tmux, command or file.
+	Clipboard string `json:"clipboard,omitempty"`
+	// ClipboardFallback is the order the backends are tried in when the
+	// preferred one fails, e.g. ["native", "tmux", "file"].
+	ClipboardFallback []string `json:"clipboardFallback,omitempty"`
+	// ClipboardFile is where the file backend writes (default: see DefaultClipboardFile).
+	ClipboardFile string `json:"clipboardFile,omitempty"`
+	// ClipboardCommand is a shell command the command backend pipes to.
+	ClipboardCommand string `json:"clipboardCommand,omitempty"`
+
+	// SplitTokens and SplitBytes cap the size of one copied part; larger
+	// bundles are copied in parts. 0 means no limit.
+	SplitTokens int `json:"splitTokens,omitempty"`
+	SplitBytes  int `json:"splitBytes,omitempty"`
+
+	// Theme is the colour theme of the UI: default, light-terminal,
+	// high-contrast, monochrome or one defined in Themes. NO_COLOR forces
+	// monochrome.
+	Theme string `json:"theme,omitempty"`
+	// Themes defines custom themes, mapping role names (e.g. "frameFocused",
+	// "selected") to styles such as "bold cyan", "208" or "#ff8700". A
+	// "base" entry names the built-in theme the others override.
+	Themes map[string]map[string]string `json:"themes,omitempty"`
+
+	// SyntaxTheme is the chroma style highlighting the Content view (default
+	// the colour theme's), or "none".
+	SyntaxTheme string `json:"syntaxTheme,omitempty"`
+	// HighlightMaxBytes is the file size above which previews are shown
+	// uncoloured (default 256 KiB).
+	HighlightMaxBytes int `json:"highlightMaxBytes,omitempty"`
+
+	// StackWidth is the terminal width below which the auto layout puts the
+	// Content view below the Files pane (default 100).
+	StackWidth int `json:"stackWidth,omitempty"`
+
+	// NoMouse leaves the mouse to the terminal (e.g. for selecting text)
+	// instead of using it for clicks, the wheel and resizing.
+	NoMouse bool `json:"noMouse,omitempty"`
+
+	// Truncate cuts down large files unless overridden per file in the Files
+	// view: head:N, tail:N, headtail:N or tokens:N. Empty means none.
+	Truncate string `json:"truncate,omitempty"`
+}
+
+// getConfigFilePath determines the path of the user config file.
+func getConfigFilePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "config.json"), nil // ~/.config/grepforllm/config.json
+}
+
+// LoadConfig reads the user config file. A missing file yields the defaults.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	path, err := getConfigFilePath()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffEdits bounds the work diffLines does; beyond it the texts are shown
+// as wholly replaced.
+const maxDiffEdits = 2000
+
+// diffOp is one line of a line diff: kind is ' ' (kept), '-' (removed from
+// the old text) or '+' (added in the new text).
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines computes a shortest line diff from a to b (Myers' algorithm).
+func diffLines(a, b []string) []diffOp {
+	// Common prefix and suffix need no search.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myersDiff diffs a and b, which should not share a prefix or suffix.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	replaceAll := func() []diffOp {
+		ops := make([]diffOp, 0, n+m)
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+	if n == 0 || m == 0 {
+		return replaceAll()
+	}
+
+	// v[k] is the furthest x reached on diagonal k = x - y. trace[d] keeps
+	// v for diagonals -d-1..d+1 as it was before round d, for backtracking.
+	limit := min(n+m, maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	found := -1
+	for d := 0; d <= limit && found < 0; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insert from b
+			} else {
+				x = v[offset+k-1] + 1 // Right: delete from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = d
+				break
+			}
+		}
+	}
+	if found < 0 {
+		return replaceAll()
+	}
+
+	var reversed []diffOp
+	x, y := n, m
+	for d := found; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			reversed = append(reversed, diffOp{'+', b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, diffOp{'-', a[x-1]})
+			x--
+		}
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
+
+// diffStats counts the added and removed lines of a diff.
+func diffStats(ops []diffOp) (added, removed int) {
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// unifiedHunks renders the changes of a diff as unified diff hunks with
+// context lines around each change.
+func unifiedHunks(ops []diffOp, context int) []string {
+	var out []string
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			} else if i-last > 2*context {
+				break
+			}
+		}
+		from, to := max(first-context, start), min(last+context+1, len(ops))
+
+		oldStart, newStart := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				oldStart++
+			}
+			if op.kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount))
+		for _, op := range ops[from:to] {
+			out = append(out, string(op.kind)+strings.TrimRight(op.line, "\n"))
+		}
+		start = to
+	}
+	return out
+}
@@ -13,53 +13,52 @@ import (
 	"github.com/awesome-gocui/gocui"
 )
 
-// ListFiles walks the directory, identifies text files, populates app.allFiles,
+// ListFiles walks every root directory, identifies text files, populates app.allFiles,
 // and then applies filters (which now include gitignore checks).
 func (app *App) ListFiles() error {
 	app.mutex.Lock() // Lock at the beginning
 
 	var files []string
-	// Walk the directory only once to find all potential files
-	err := filepath.WalkDir(app.rootDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			if os.IsPermission(err) {
-				// Log permission errors? For now, just skip.
-				fmt.Fprintf(os.Stderr, "Warning: Skipping directory due to permission error: %s\n", path)
-				return filepath.SkipDir // Skip directories we can't read
-			}
-			// Log other walk errors?
-			fmt.Fprintf(os.Stderr, "Warning: Error accessing path %s: %v\n", path, err)
-			return nil // Continue if possible, skip the problematic entry
-		}
+	skipped := make(map[string]SkippedFile)
+	symlinked := make(map[string]string) // display path -> link target, for the Files view marker
+	listedLinks := make(map[string]string)
 
-		// Skip root itself
-		if path == app.rootDir {
-			return nil
-		}
+	for _, root := range app.roots {
+		// Entries are keyed by display path: "label/relpath" when several roots are open.
+		prefix := app.displayPrefix(root)
 
-		relPath, err := filepath.Rel(app.rootDir, path)
+		// Walk from the resolved root so loop detection and root-escape checks
+		// compare real paths on both sides.
+		rootReal, err := filepath.EvalSymlinks(root.Dir)
 		if err != nil {
-			// Should not happen if path is within rootDir
-			fmt.Fprintf(os.Stderr, "Warning: Could not get relative path for %s: %v\n", path, err)
-			return nil
+			rootReal = root.Dir
 		}
-		relPathSlash := filepath.ToSlash(relPath) // Use slash-separated path consistently
+		followedDirs := map[string]string{rootReal: "."} // real dir -> relative path it was walked as
 
-		// --- Directory Handling ---
-		if d.IsDir() {
+		// skipDirectory reports whether a directory should not be descended into,
+		// recording the reason. dirPathWithSlash is relative to root.Dir.
+		skipDirectory := func(dirPathWithSlash, name string) bool {
 			// Check gitignore for directories FIRST. If ignored, skip the whole dir.
 			// This is more efficient than checking every file inside.
-			// Note: The matcher needs the path relative to the gitignore location (rootDir).
+			// Note: The matcher needs the path relative to the gitignore location (root.Dir).
 			// The go-gitignore library expects paths relative to the .gitignore file's location.
 			// We also need to check if the directory *itself* matches a pattern.
 			// Add a trailing slash for directory matching consistency with gitignore rules.
-			if app.gitignoreMatcher != nil && app.gitignoreMatcher.Ignore(relPathSlash+"/") {
-				return filepath.SkipDir
+
+			// Skip .git directory explicitly, before gitignore or DefaultExcludes can claim it,
+			// so it is always reported as repository metadata.
+			if name == ".git" {
+				skipped[prefix+dirPathWithSlash] = SkippedFile{Reason: SkipMetadata}
+				return true
+			}
+
+			if root.ignores(strings.TrimSuffix(dirPathWithSlash, "/"), true) {
+				skipped[prefix+dirPathWithSlash] = SkippedFile{Reason: SkipGitignore}
+				return true
 			}
 
 			// Simple check for default excluded *directories* during walk
 			// This prevents descending into large unwanted dirs like .git or node_modules
-			dirPathWithSlash := relPathSlash + "/"
 			for _, pattern := range strings.Split(DefaultExcludes, ",") {
 				pattern = strings.TrimSpace(pattern)
 				if pattern == "" || !strings.HasSuffix(pattern, "/") {
@@ -67,81 +66,231 @@ func (app *App) ListFiles() error {
 				}
 				pattern = filepath.ToSlash(pattern)
 				if strings.HasPrefix(dirPathWithSlash, pattern) {
-					return filepath.SkipDir
+					skipped[prefix+dirPathWithSlash] = SkippedFile{Reason: SkipDefaultExclude, Detail: pattern}
+					return true
 				}
 			}
-			// Also skip .git directory explicitly if not caught by DefaultExcludes
-			// (Gitignore check above should handle this too if .git is in .gitignore)
-			if d.Name() == ".git" {
-				return filepath.SkipDir
-			}
-			return nil // Continue walking in this directory
+			return false
 		}
 
-		// --- File Handling ---
+		// walkTree walks walkRoot (a real path), reporting entries relative to root.Dir
+		// by prefixing relBase. It recurses into symlinked directories under SymlinkFollow.
+		var walkTree func(walkRoot, relBase string) error
+		walkTree = func(walkRoot, relBase string) error {
+			return filepath.WalkDir(walkRoot, func(path string, d fs.DirEntry, err error) error {
+				relPathSlash := relBase
+				if relPath, relErr := filepath.Rel(walkRoot, path); relErr != nil {
+					// Should not happen if path is within walkRoot
+					fmt.Fprintf(os.Stderr, "Warning: Could not get relative path for %s: %v\n", path, relErr)
+					return nil
+				} else if relPath != "." {
+					relPathSlash = strings.TrimPrefix(relBase+"/"+filepath.ToSlash(relPath), "/") // Use slash-separated path consistently
+				}
+				displayPath := prefix + relPathSlash
+
+				if err != nil {
+					relErrPath := displayPath
+					if relPathSlash == "" {
+						relErrPath = prefix + "."
+					} else if d != nil && d.IsDir() {
+						relErrPath += "/"
+					}
+					if os.IsPermission(err) {
+						// Record instead of printing: stderr is hidden underneath the TUI.
+						skipped[relErrPath] = SkippedFile{Reason: SkipPermission, Detail: err.Error()}
+						return filepath.SkipDir // Skip directories we can't read
+					}
+					skipped[relErrPath] = SkippedFile{Reason: SkipReadError, Detail: err.Error()}
+					return nil // Continue if possible, skip the problematic entry
+				}
 
-		// Skip .gitignore file itself (already handled by LoadGitignoreMatcher not walking)
-		// but double-check here just in case.
-		if relPathSlash == ".gitignore" {
-			return nil
-		}
+				// Skip the walk root itself
+				if path == walkRoot {
+					return nil
+				}
+
+				// --- Symlink Handling ---
+				if d.Type()&fs.ModeSymlink != 0 {
+					target, _ := os.Readlink(path)
+					if root.ignores(relPathSlash, false) || root.ignores(relPathSlash, true) {
+						skipped[displayPath] = SkippedFile{Reason: SkipGitignore}
+						return nil
+					}
+
+					switch app.symlinkPolicy {
+					case SymlinkSkip:
+						skipped[displayPath] = SkippedFile{Reason: SkipSymlink, Detail: "-> " + target}
+						return nil
+					case SymlinkList:
+						// The link itself becomes the entry; its content is a description.
+						files = append(files, displayPath)
+						symlinked[displayPath] = target
+						listedLinks[displayPath] = target
+						return nil
+					}
+
+					// SymlinkFollow
+					realTarget, evalErr := filepath.EvalSymlinks(path)
+					if evalErr != nil {
+						skipped[displayPath] = SkippedFile{Reason: SkipSymlink, Detail: "dangling -> " + target}
+						return nil
+					}
+					if !app.allowSymlinkEscape && !isWithinDir(rootReal, realTarget) {
+						skipped[displayPath] = SkippedFile{Reason: SkipSymlink, Detail: "escapes root -> " + target}
+						return nil
+					}
+					info, statErr := os.Stat(realTarget)
+					if statErr != nil {
+						skipped[displayPath] = SkippedFile{Reason: SkipReadError, Detail: statErr.Error()}
+						return nil
+					}
+					if info.IsDir() {
+						dirPathWithSlash := relPathSlash + "/"
+						// A link to the directory containing it (or any ancestor) would recurse forever.
+						if isWithinDir(realTarget, filepath.Dir(path)) {
+							skipped[prefix+dirPathWithSlash] = SkippedFile{Reason: SkipSymlink, Detail: "loop -> " + target}
+							return nil
+						}
+						if via, seen := followedDirs[realTarget]; seen {
+							skipped[prefix+dirPathWithSlash] = SkippedFile{Reason: SkipSymlink, Detail: "already followed via " + via}
+							return nil
+						}
+						if skipDirectory(dirPathWithSlash, d.Name()) {
+							return nil
+						}
+						followedDirs[realTarget] = relPathSlash
+						before := len(files)
+						_ = walkTree(realTarget, relPathSlash)
+						for _, file := range files[before:] {
+							symlinked[file] = target
+						}
+						return nil
+					}
+					// Symlinked file: fall through to the regular file checks below,
+					// which read through the link.
+					symlinked[displayPath] = target
+				}
 
-		// Check gitignore for the file path *before* opening/reading it
-		if app.gitignoreMatcher != nil && app.gitignoreMatcher.Ignore(relPathSlash) {
-			return nil // Skip ignored file
+				// --- Directory Handling ---
+				if d.IsDir() {
+					if skipDirectory(relPathSlash+"/", d.Name()) {
+						return filepath.SkipDir
+					}
+					return nil // Continue walking in this directory
+				}
+
+				// --- File Handling ---
+
+				// Skip .gitignore file itself (already handled by LoadGitignoreMatcher not walking)
+				// but double-check here just in case.
+				if relPathSlash == ".gitignore" {
+					skipped[displayPath] = SkippedFile{Reason: SkipMetadata}
+					return nil
+				}
+
+				// Check gitignore for the file path *before* opening/reading it
+				if root.ignores(relPathSlash, false) {
+					skipped[displayPath] = SkippedFile{Reason: SkipGitignore}
+					return nil // Skip ignored file
+				}
+
+				// --- Binary File Check (only for files not ignored) ---
+				// Optimization: Stat first to check size?
+				// info, err := d.Info()
+				// if err != nil {
+				// 	// Error getting file info, skip
+				// 	fmt.Fprintf(os.Stderr, "Warning: Could not get file info for %s: %v\n", path, err)
+				// 	return nil
+				// }
+				// if info.Size() == 0 { // Skip empty files
+				// 	return nil
+				// }
+				// Optional: Add a max size check here to avoid reading huge files
+				// if info.Size() > MaxFileSizeBytes { return nil }
+
+				file, err := os.Open(path)
+				if err != nil {
+					reason := SkipReadError
+					if os.IsPermission(err) {
+						reason = SkipPermission
+					}
+					skipped[displayPath] = SkippedFile{Reason: reason, Detail: err.Error()}
+					delete(symlinked, displayPath)
+					return nil // Skip files we cannot open
+				}
+				defer file.Close()
+
+				buffer := make([]byte, 512) // Read a small chunk to detect content type
+				n, err := file.Read(buffer)
+				if err != nil && err != io.EOF {
+					skipped[displayPath] = SkippedFile{Reason: SkipReadError, Detail: err.Error()}
+					delete(symlinked, displayPath)
+					return nil // Skip files we cannot read
+				}
+
+				// Check if it's likely a text file
+				contentType := http.DetectContentType(buffer[:n])
+				// Be a bit more lenient? Allow application/json, etc.?
+				// For now, stick to text/*
+				if !strings.HasPrefix(contentType, "text/") {
+					skipped[displayPath] = SkippedFile{Reason: SkipBinary, Detail: contentType}
+					delete(symlinked, displayPath)
+					return nil // Skip binary files
+				}
+
+				// If it's a text file and not ignored, add its display path to the list
+				files = append(files, displayPath)
+				return nil
+			})
 		}
 
-		// --- Binary File Check (only for files not ignored) ---
-		// Optimization: Stat first to check size?
-		// info, err := d.Info()
-		// if err != nil {
-		// 	// Error getting file info, skip
-		// 	fmt.Fprintf(os.Stderr, "Warning: Could not get file info for %s: %v\n", path, err)
-		// 	return nil
-		// }
-		// if info.Size() == 0 { // Skip empty files
-		// 	return nil
-		// }
-		// Optional: Add a max size check here to avoid reading huge files
-		// if info.Size() > MaxFileSizeBytes { return nil }
-
-		file, err := os.Open(path)
+		// Walk each root only once to find all potential files
+		err = walkTree(rootReal, "")
+		// Unlock should happen *before* calling applyFilters if applyFilters acquires lock
+		// Or, applyFilters should assume lock is held. Let's assume applyFilters needs the lock.
+		// app.mutex.Unlock() // Unlock before calling applyFilters
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not open file %s: %v\n", path, err)
-			return nil // Skip files we cannot open
+			app.mutex.Unlock() // Ensure unlock on error during walk
+			return fmt.Errorf("error walking directory %s: %w", root.Dir, err)
 		}
-		defer file.Close()
+	}
 
-		buffer := make([]byte, 512) // Read a small chunk to detect content type
-		n, err := file.Read(buffer)
-		if err != nil && err != io.EOF {
-			fmt.Fprintf(os.Stderr, "Warning: Could not read file %s: %v\n", path, err)
-			return nil // Skip files we cannot read
+	// Expanded directories keep listing their files (see ToggleForceInclude).
+	for dir := range app.expandedDirs {
+		if entry, ok := skipped[dir]; ok {
+			for _, path := range app.skippedDirFiles(dir) {
+				skipped[path] = SkippedFile{Reason: entry.Reason, Detail: "in " + dir}
+			}
 		}
+	}
 
-		// Check if it's likely a text file
-		contentType := http.DetectContentType(buffer[:n])
-		// Be a bit more lenient? Allow application/json, etc.?
-		// For now, stick to text/*
-		if !strings.HasPrefix(contentType, "text/") {
-			// Optionally log skipped binary files: log.Printf("Skipping binary file: %s (type: %s)", relPathSlash, contentType)
-			return nil // Skip binary files
+	// Pull force-included paths back in, even when the walk never reached them
+	// (e.g. files inside a gitignored directory, listed by expanding it in the
+	// Skipped view).
+	discovered := make(map[string]bool, len(files))
+	for _, file := range files {
+		discovered[file] = true
+	}
+	for path := range app.forceIncluded {
+		if discovered[path] || strings.HasSuffix(path, "/") {
+			continue
 		}
-
-		// If it's a text file and not ignored, add its relative path to the list
-		files = append(files, relPathSlash)
-		return nil
-	})
-	// Unlock should happen *before* calling applyFilters if applyFilters acquires lock
-	// Or, applyFilters should assume lock is held. Let's assume applyFilters needs the lock.
-	// app.mutex.Unlock() // Unlock before calling applyFilters
-	if err != nil {
-		app.mutex.Unlock() // Ensure unlock on error during walk
-		return fmt.Errorf("error walking directory %s: %w", app.rootDir, err)
+		fullPath, ok := app.resolvePath(path)
+		if !ok {
+			continue
+		}
+		info, statErr := os.Stat(fullPath)
+		if statErr != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, path)
 	}
 
 	sort.Strings(files)  // Sort all discovered text files
 	app.allFiles = files // Store the complete list
+	app.skippedFiles = skipped
+	app.symlinkedFiles = symlinked
+	app.listedSymlinks = listedLinks
 
 	// applyFilters will now use the gitignore info via shouldIncludeFile
 	// It also unlocks the mutex.
@@ -149,6 +298,40 @@ func (app *App) ListFiles() error {
 	return nil
 }
 
+// maxExpandedFiles caps the files listed for one expanded directory.
+const maxExpandedFiles = 500
+
+// skippedDirFiles returns the regular files under the skipped directory dir
+// (a display path ending in "/"), at most maxExpandedFiles of them. Nested
+// .git directories are not entered. Roots never change after NewApp, so the
+// mutex may or may not be held.
+func (app *App) skippedDirFiles(dir string) []string {
+	fullDir, ok := app.resolvePath(strings.TrimSuffix(dir, "/"))
+	if !ok {
+		return nil
+	}
+	var paths []string
+	_ = filepath.WalkDir(fullDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(paths) == maxExpandedFiles {
+			return filepath.SkipAll
+		}
+		if relPath, err := filepath.Rel(fullDir, path); err == nil {
+			paths = append(paths, dir+filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	return paths
+}
+
 // applyFilters filters app.allFiles into app.fileList based on current filter settings.
 // It assumes the mutex is held when called and unlocks it upon completion.
 func (app *App) applyFilters() {
@@ -156,6 +339,7 @@ func (app *App) applyFilters() {
 
 	filteredList := []string{}
 	newSelectedFiles := make(map[string]bool)
+	newFilterSkipped := make(map[string]SkippedFile)
 
 	// Read filter state under lock
 	currentFilterMode := app.filterMode
@@ -169,7 +353,13 @@ func (app *App) applyFilters() {
 		// Pass the gitignoreMatcher to shouldIncludeFile or rely on allFiles being pre-filtered?
 		// Let's modify shouldIncludeFile to *only* check default/user filters,
 		// assuming gitignore filtering happened during ListFiles walk.
-		if app.shouldIncludeFileByFilters(file, currentFilterMode, currentIncludes, currentExcludes) {
+		included := app.shouldIncludeFileByFilters(file, currentFilterMode, currentIncludes, currentExcludes)
+		if !included {
+			// Record the rejection even for force-included files, so the
+			// Skipped view can still offer to undo the override.
+			newFilterSkipped[file] = app.filterSkipReason(file, currentFilterMode)
+		}
+		if included || app.forceIncluded[file] {
 			filteredList = append(filteredList, file)
 			// Preserve selection state if the file remains visible
 			if app.selectedFiles[file] {
@@ -180,6 +370,12 @@ func (app *App) applyFilters() {
 
 	app.fileList = filteredList
 	app.selectedFiles = newSelectedFiles
+	for file := range app.lineRanges {
+		if !newSelectedFiles[file] {
+			delete(app.lineRanges, file) // Partial selections follow the selection
+		}
+	}
+	app.filterSkipped = newFilterSkipped
 
 	// Adjust cursor if it's now out of bounds
 	if app.currentLine >= len(app.fileList) {
@@ -216,32 +412,8 @@ func (app *App) shouldIncludeFileByFilters(relPath string, filterMode FilterMode
 	// 1. Check Default Excludes (applied regardless of include/exclude mode, unless overridden by include)
 	// We apply default excludes *before* include mode checks, except when include mode specifically matches the file.
 	isDefaultExcluded := false
-	for _, pattern := range strings.Split(DefaultExcludes, ",") {
-		pattern = strings.TrimSpace(pattern)
-		if pattern == "" {
-			continue
-		}
-		pattern = filepath.ToSlash(pattern)
-
-		if strings.HasSuffix(pattern, "/") { // Directory pattern
-			// Check if the file's directory path starts with the pattern
-			// Example: pattern="node_modules/", dirPath="node_modules/some_lib/" -> match
-			if strings.HasPrefix(dirPath, pattern) {
-				isDefaultExcluded = true
-				break
-			}
-		} else { // File pattern
-			// Check against base name first (e.g., *.log)
-			if matched, _ := filepath.Match(pattern, baseName); matched {
-				isDefaultExcluded = true
-				break
-			}
-			// Check against full relative path (e.g., specific/file.txt)
-			if matched, _ := filepath.Match(pattern, relPath); matched {
-				isDefaultExcluded = true
-				break
-			}
-		}
+	if _, rootRel, ok := app.splitDisplayPath(relPath); ok {
+		isDefaultExcluded = matchesDefaultExcludes(rootRel)
 	}
 
 	// 2. Apply Filter Mode Logic
@@ -319,6 +491,122 @@ func (app *App) shouldIncludeFileByFilters(relPath string, filterMode FilterMode
 	}
 }
 
+// matchesDefaultExcludes reports whether relPath (slash format) matches DefaultExcludes.
+func matchesDefaultExcludes(relPath string) bool {
+	baseName := filepath.Base(relPath)
+	dirPath := filepath.Dir(relPath)
+	if dirPath == "." {
+		dirPath = ""
+	} else {
+		dirPath += "/"
+	}
+
+	for _, pattern := range strings.Split(DefaultExcludes, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		pattern = filepath.ToSlash(pattern)
+
+		if strings.HasSuffix(pattern, "/") { // Directory pattern
+			// Check if the file's directory path starts with the pattern
+			// Example: pattern="node_modules/", dirPath="node_modules/some_lib/" -> match
+			if strings.HasPrefix(dirPath, pattern) {
+				return true
+			}
+		} else { // File pattern
+			// Check against base name first (e.g., *.log)
+			if matched, _ := filepath.Match(pattern, baseName); matched {
+				return true
+			}
+			// Check against full relative path (e.g., specific/file.txt)
+			if matched, _ := filepath.Match(pattern, relPath); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterSkipReason explains why shouldIncludeFileByFilters rejected relPath.
+func (app *App) filterSkipReason(relPath string, filterMode FilterMode) SkippedFile {
+	if _, rootRel, ok := app.splitDisplayPath(relPath); ok && matchesDefaultExcludes(rootRel) {
+		return SkippedFile{Reason: SkipDefaultExclude}
+	}
+	if filterMode == IncludeMode {
+		return SkippedFile{Reason: SkipFilter, Detail: "no include pattern matched"}
+	}
+	return SkippedFile{Reason: SkipFilter, Detail: "matched an exclude pattern"}
+}
+
+// isWithinDir reports whether path is dir itself or located below it.
+// Both arguments are expected to be absolute, cleaned paths.
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// readFileContent returns the content of the file at display path relPath as it
+// should be previewed, counted and copied. Symlinks listed under SymlinkList are
+// described rather than dereferenced.
+func (app *App) readFileContent(relPath string) ([]byte, error) {
+	app.mutex.Lock()
+	target, isListedLink := app.listedSymlinks[relPath]
+	fullPath, ok := app.resolvePath(relPath)
+	app.mutex.Unlock()
+
+	if isListedLink {
+		return []byte(fmt.Sprintf("symlink -> %s\n", target)), nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("no root directory for %s", relPath)
+	}
+	return os.ReadFile(fullPath)
+}
+
+// displayPrefix returns the prefix added to entries of root: empty for
+// single-root sessions, "label/" otherwise. Assumes mutex is held.
+func (app *App) displayPrefix(root Root) string {
+	if len(app.roots) <= 1 {
+		return ""
+	}
+	return root.Label + "/"
+}
+
+// splitDisplayPath maps a display path to its root and the path relative to
+// that root. Assumes mutex is held.
+func (app *App) splitDisplayPath(displayPath string) (Root, string, bool) {
+	if len(app.roots) == 0 {
+		return Root{}, "", false
+	}
+	if len(app.roots) == 1 {
+		return app.roots[0], displayPath, true
+	}
+	label, relPath, found := strings.Cut(displayPath, "/")
+	if !found {
+		return Root{}, "", false
+	}
+	for _, root := range app.roots {
+		if root.Label == label {
+			return root, relPath, true
+		}
+	}
+	return Root{}, "", false
+}
+
+// resolvePath returns the absolute filesystem path for a display path.
+// Assumes mutex is held.
+func (app *App) resolvePath(displayPath string) (string, bool) {
+	root, relPath, ok := app.splitDisplayPath(displayPath)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(root.Dir, filepath.FromSlash(relPath)), true
+}
+
 func (app *App) SetLoadingComplete(err error) {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files, relative to dir, with their content.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newWalkApp returns an App for the given root directories, set up as NewApp
+// does but without a tokenizer or cache, with each root's .gitignore loaded.
+func newWalkApp(t *testing.T, rootDirs ...string) *App {
+	t.Helper()
+	app := &App{
+		roots:          newRoots(rootDirs),
+		selectedFiles:  make(map[string]bool),
+		filterMode:     ExcludeMode,
+		excludes:       DefaultExcludes,
+		lineRanges:     make(map[string][]LineRange),
+		renderModes:    make(map[string]RenderMode),
+		truncations:    make(map[string]Truncation),
+		skippedFiles:   make(map[string]SkippedFile),
+		filterSkipped:  make(map[string]SkippedFile),
+		forceIncluded:  make(map[string]bool),
+		expandedDirs:   make(map[string]bool),
+		symlinkPolicy:  SymlinkFollow,
+		symlinkedFiles: make(map[string]string),
+		listedSymlinks: make(map[string]string),
+	}
+	for _, rootDir := range rootDirs {
+		matcher, err := LoadGitignoreMatcher(rootDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matcher != nil {
+			app.SetGitignoreMatcher(rootDir, matcher)
+		}
+	}
+	return app
+}
+
+// listFiles runs ListFiles and returns the files found.
+func listFiles(t *testing.T, app *App) []string {
+	t.Helper()
+	if err := app.ListFiles(); err != nil {
+		t.Fatal(err)
+	}
+	return app.allFiles
+}
+
+// TestForceIncludeInIgnoredDir checks that the files of a gitignored
+// directory can be listed in the Skipped view and force-included.
+func TestForceIncludeInIgnoredDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":    "build/\n",
+		"main.go":       "package main\n",
+		"build/out.txt": "output\n",
+		"build/gen/a.c": "int a;\n",
+	})
+	app := newWalkApp(t, dir)
+
+	files := listFiles(t, app)
+	if !slices.Equal(files, []string{"main.go"}) || app.skippedFiles["build/"].Reason != SkipGitignore {
+		t.Fatalf("files = %v, skipped = %v", files, app.skippedFiles)
+	}
+
+	// Expanded, the directory lists its files, and keeps listing them.
+	app.expandedDirs["build/"] = true
+	listFiles(t, app)
+	for _, path := range []string{"build/out.txt", "build/gen/a.c"} {
+		if entry := app.skippedFiles[path]; entry.Reason != SkipGitignore || entry.Detail != "in build/" {
+			t.Errorf("%s skipped as %+v, want listed in build/", path, entry)
+		}
+	}
+
+	app.forceIncluded["build/out.txt"] = true
+	if files := listFiles(t, app); !slices.Equal(files, []string{"build/out.txt", "main.go"}) {
+		t.Errorf("files = %v, want build/out.txt force-included", files)
+	}
+	if !slices.Contains(app.fileList, "build/out.txt") {
+		t.Errorf("fileList = %v, want build/out.txt past the filters", app.fileList)
+	}
+}
+
+// symlinkTree creates a root with links to a file, to directories (twice to
+// the same one), back to the root (loops), out of the root and to nothing, and
+// returns the root.
+func symlinkTree(t *testing.T) string {
+	t.Helper()
+	dir, outside := t.TempDir(), t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "a\n", "sub/b.txt": "b\n"})
+	writeTree(t, outside, map[string]string{"c.txt": "c\n"})
+	links := map[string]string{
+		"alias":    "sub",
+		"alias2":   "sub",
+		"link.txt": "a.txt",
+		"loop":     ".",
+		"sub/up":   "..",
+		"out":      outside,
+		"dangling": "missing.txt",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestListFilesSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  SymlinkPolicy
+		escape  bool
+		files   []string
+		skipped map[string]string // Path -> start of the detail, all for SkipSymlink
+	}{
+		{
+			name:   "follow",
+			policy: SymlinkFollow,
+			files:  []string{"a.txt", "alias/b.txt", "link.txt", "sub/b.txt"},
+			skipped: map[string]string{
+				"alias2/":  "already followed via alias",
+				"loop/":    "loop",
+				"sub/up/":  "loop",
+				"out":      "escapes root",
+				"dangling": "dangling",
+			},
+		},
+		{
+			name:   "follow out of the root",
+			policy: SymlinkFollow,
+			escape: true,
+			files:  []string{"a.txt", "alias/b.txt", "link.txt", "out/c.txt", "sub/b.txt"},
+			skipped: map[string]string{
+				"alias2/":  "already followed via alias",
+				"loop/":    "loop",
+				"sub/up/":  "loop",
+				"dangling": "dangling",
+			},
+		},
+		{
+			name:   "list",
+			policy: SymlinkList,
+			files:  []string{"a.txt", "alias", "alias2", "dangling", "link.txt", "loop", "out", "sub/b.txt", "sub/up"},
+		},
+		{
+			name:   "skip",
+			policy: SymlinkSkip,
+			files:  []string{"a.txt", "sub/b.txt"},
+			skipped: map[string]string{
+				"alias": "->", "alias2": "->", "link.txt": "->", "loop": "->", "sub/up": "->", "out": "->", "dangling": "->",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newWalkApp(t, symlinkTree(t))
+			app.symlinkPolicy, app.allowSymlinkEscape = tt.policy, tt.escape
+
+			if files := listFiles(t, app); !slices.Equal(files, tt.files) {
+				t.Errorf("files = %v, want %v", files, tt.files)
+			}
+			for path, detail := range tt.skipped {
+				entry, ok := app.skippedFiles[path]
+				if !ok || entry.Reason != SkipSymlink || !strings.HasPrefix(entry.Detail, detail) {
+					t.Errorf("%s skipped as %+v (%v), want a symlink skip %q...", path, entry, ok, detail)
+				}
+			}
+			if tt.policy == SymlinkList && app.listedSymlinks["link.txt"] != "a.txt" {
+				t.Errorf("listed links = %v, want link.txt -> a.txt", app.listedSymlinks)
+			}
+			if tt.policy == SymlinkFollow && (app.symlinkedFiles["alias/b.txt"] != "sub" || app.symlinkedFiles["link.txt"] != "a.txt") {
+				t.Errorf("symlinked files = %v, want alias/b.txt and link.txt marked", app.symlinkedFiles)
+			}
+		})
+	}
+}
+
+func TestIsWithinDir(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		want      bool
+	}{
+		{"/src", "/src", true},
+		{"/src", "/src/a/b", true},
+		{"/src", "/src/..a", true},
+		{"/src", "/", false},
+		{"/src", "/srcs/a", false},
+		{"/src/a", "/src/b", false},
+	}
+	for _, tt := range tests {
+		if got := isWithinDir(tt.dir, tt.path); got != tt.want {
+			t.Errorf("isWithinDir(%q, %q) = %v, want %v", tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNewRoots(t *testing.T) {
+	roots := newRoots([]string{"/work/src", "/home/src", "/opt/src", "/", "/srv/api"})
+	var labels []string
+	for _, root := range roots {
+		labels = append(labels, root.Label)
+	}
+	if want := []string{"src", "src-2", "src-3", "root", "api"}; !slices.Equal(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+}
+
+// TestMultiRootPaths checks the labelled display paths of two roots with
+// the same directory name, and the mapping between them and absolute paths.
+func TestMultiRootPaths(t *testing.T) {
+	base := t.TempDir()
+	first, second := filepath.Join(base, "one", "app"), filepath.Join(base, "two", "app")
+	writeTree(t, first, map[string]string{"main.go": "package main\n", "lib/a.go": "package lib\n"})
+	writeTree(t, second, map[string]string{"main.go": "package main\n"})
+	app := newWalkApp(t, first, second)
+
+	files := listFiles(t, app)
+	if want := []string{"app-2/main.go", "app/lib/a.go", "app/main.go"}; !slices.Equal(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+
+	for _, file := range files {
+		root, relPath, ok := app.splitDisplayPath(file)
+		if !ok || app.displayPrefix(root)+relPath != file {
+			t.Errorf("splitDisplayPath(%s) = %s, %s, %v", file, root.Label, relPath, ok)
+			continue
+		}
+		absPath, _ := app.resolvePath(file)
+		if _, err := os.Stat(absPath); err != nil {
+			t.Errorf("resolvePath(%s) = %s: %v", file, absPath, err)
+		}
+		if back, err := filepath.Rel(root.Dir, absPath); err != nil || filepath.ToSlash(back) != relPath {
+			t.Errorf("%s resolves to %s, outside its root %s", file, absPath, root.Dir)
+		}
+	}
+	if absPath, _ := app.resolvePath("app-2/main.go"); absPath != filepath.Join(second, "main.go") {
+		t.Errorf("app-2/main.go resolves to %s, want the second root", absPath)
+	}
+	for _, path := range []string{"main.go", "other/main.go"} {
+		if _, ok := app.resolvePath(path); ok {
+			t.Errorf("resolvePath(%s) succeeded without a known label", path)
+		}
+	}
+}
+
+func TestSessionCacheKey(t *testing.T) {
+	if key := sessionCacheKey([]string{"/a"}); key != "/a" {
+		t.Errorf("single root key = %q, want the directory", key)
+	}
+	if sessionCacheKey([]string{"/b", "/a"}) != sessionCacheKey([]string{"/a", "/b"}) {
+		t.Error("the key of several roots depends on their order")
+	}
+}
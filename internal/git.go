@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findGitDir walks up from dir to the repository's git directory, following
+// the "gitdir:" indirection used by worktrees and submodules. It returns ""
+// outside a repository.
+func findGitDir(dir string) string {
+	for {
+		candidate := filepath.Join(dir, ".git")
+		if info, err := os.Stat(candidate); err == nil {
+			if info.IsDir() {
+				return candidate
+			}
+			if data, err := os.ReadFile(candidate); err == nil {
+				if target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:"); ok {
+					target = strings.TrimSpace(target)
+					if !filepath.IsAbs(target) {
+						target = filepath.Join(dir, target)
+					}
+					return target
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// gitBranch returns the checked-out branch of the repository containing dir,
+// the short commit hash for a detached HEAD, or "" outside a repository.
+func gitBranch(dir string) string {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return ""
+	}
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+		return branch
+	}
+	if len(ref) >= 7 {
+		return ref[:7] // Detached HEAD
+	}
+	return ""
+}
+
+// gitCommit returns the full hash of the commit checked out in the repository
+// containing dir, or "" if it cannot be resolved (outside a repository, or an
+// unborn branch).
+func gitCommit(dir string) string {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return ""
+	}
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !ok {
+		return ref // Detached HEAD holds the hash itself
+	}
+
+	// Worktrees keep shared refs in the common directory.
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	for _, d := range []string{gitDir, commonDir} {
+		if data, err := os.ReadFile(filepath.Join(d, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+
+	// Refs not stored loose are listed in packed-refs as "<hash> <ref>".
+	packed, err := os.ReadFile(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(packed), "\n") {
+		if hash, name, ok := strings.Cut(strings.TrimSpace(line), " "); ok && name == ref {
+			return hash
+		}
+	}
+	return ""
+}
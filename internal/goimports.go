@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"bufio"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultImportDepth is how many levels of in-module imports ExpandGoImports
+// follows unless --import-depth says otherwise.
+const DefaultImportDepth = 1
+
+// goModule is the Go module a directory belongs to.
+type goModule struct {
+	dir  string // Directory containing go.mod
+	path string // Module path from its module directive
+}
+
+// goPackage is a Go package directory reached while expanding imports.
+type goPackage struct {
+	dir   string
+	name  string // Package name, empty to accept the directory's non-test package
+	depth int    // Import hops from the package of a seed file
+}
+
+// goFileInfo is the parsed header of a Go file.
+type goFileInfo struct {
+	pkg     string
+	imports []string
+}
+
+// parseGoHeader reads the package name and import paths of a Go file.
+func parseGoHeader(path string) (goFileInfo, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+	if err != nil {
+		return goFileInfo{}, err
+	}
+	info := goFileInfo{pkg: file.Name.Name}
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			info.imports = append(info.imports, importPath)
+		}
+	}
+	return info, nil
+}
+
+// findGoModule walks up from dir to the nearest go.mod and returns its module,
+// or nil if dir is not inside a module. Results are memoised in modules.
+func findGoModule(dir string, modules map[string]*goModule) *goModule {
+	if mod, ok := modules[dir]; ok {
+		return mod
+	}
+
+	var mod *goModule
+	if modulePath, err := readModulePath(filepath.Join(dir, "go.mod")); err == nil {
+		mod = &goModule{dir: dir, path: modulePath}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mod = findGoModule(parent, modules)
+	}
+	modules[dir] = mod
+	return mod
+}
+
+// readModulePath returns the module path declared in a go.mod file.
+func readModulePath(goModPath string) (string, error) {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+				return unquoted, nil
+			}
+			return fields[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", os.ErrNotExist
+}
+
+// importDir returns the directory of importPath if it belongs to mod.
+func (mod *goModule) importDir(importPath string) (string, bool) {
+	if importPath == mod.path {
+		return mod.dir, true
+	}
+	rest, ok := strings.CutPrefix(importPath, mod.path+"/")
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(mod.dir, filepath.FromSlash(rest)), true
+}
+
+// goPackageFiles returns the visible files belonging to the Go packages of the
+// seed files and, up to depth import hops, the in-module packages they import.
+// Test files are only included as seeds. Assumes mutex is held.
+func (app *App) goPackageFiles(seeds []string, depth int) []string {
+	// Visible non-test Go files by directory.
+	filesByDir := make(map[string][]string)
+	absPaths := make(map[string]string)
+	for _, relPath := range app.fileList {
+		if !strings.HasSuffix(relPath, ".go") || strings.HasSuffix(relPath, "_test.go") {
+			continue
+		}
+		if absPath, ok := app.resolvePath(relPath); ok {
+			dir := filepath.Dir(absPath)
+			filesByDir[dir] = append(filesByDir[dir], relPath)
+			absPaths[relPath] = absPath
+		}
+	}
+
+	headers := make(map[string]goFileInfo)
+	header := func(relPath, absPath string) (goFileInfo, bool) {
+		if info, ok := headers[relPath]; ok {
+			return info, true
+		}
+		info, err := parseGoHeader(absPath)
+		if err != nil {
+			return goFileInfo{}, false
+		}
+		headers[relPath] = info
+		return info, true
+	}
+
+	var queue []goPackage
+	for _, relPath := range seeds {
+		absPath, ok := app.resolvePath(relPath)
+		if !ok {
+			continue
+		}
+		if info, ok := header(relPath, absPath); ok {
+			queue = append(queue, goPackage{dir: filepath.Dir(absPath), name: info.pkg})
+		}
+	}
+
+	modules := make(map[string]*goModule)
+	visited := make(map[string]bool)
+	var result []string
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if visited[pkg.dir] {
+			continue
+		}
+		visited[pkg.dir] = true
+
+		mod := findGoModule(pkg.dir, modules)
+		for _, relPath := range filesByDir[pkg.dir] {
+			info, ok := header(relPath, absPaths[relPath])
+			if !ok || (pkg.name != "" && info.pkg != pkg.name) {
+				continue // Unparsable, or another package in the same directory
+			}
+			result = append(result, relPath)
+
+			if mod == nil || pkg.depth >= depth {
+				continue
+			}
+			for _, importPath := range info.imports {
+				// A nested module's packages are not part of mod, despite the
+				// import path prefix.
+				if dir, ok := mod.importDir(importPath); ok && !visited[dir] && findGoModule(dir, modules) == mod {
+					queue = append(queue, goPackage{dir: dir, depth: pkg.depth + 1})
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestGoPackageFiles expands seeds in a module holding a nested module. Test
+// files, other packages in the same directory, unparsable files and the nested
+// module imported through the main module's path are never added.
+func TestGoPackageFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod":           "module example.com/m // main module\n\ngo 1.24\n",
+		"a/a.go":           "package a\n\nimport (\n\t\"fmt\"\n\t\"example.com/m/b\"\n\t\"example.com/m/nested/d\"\n)\n",
+		"a/a_test.go":      "package a\n",
+		"a/ext_test.go":    "package a_test\n\nimport \"example.com/m/c\"\n",
+		"a/gen.go":         "//go:build ignore\n\npackage main\n",
+		"b/b.go":           "package b\n\nimport \"example.com/m/c\"\n",
+		"c/c.go":           "package c\n",
+		"c/broken.go":      "pakage c\n",
+		"nested/go.mod":    "module \"example.com/nested\"\n",
+		"nested/n.go":      "package nested\n\nimport (\n\t\"example.com/m/c\"\n\t\"example.com/nested/d\"\n)\n",
+		"nested/d/d.go":    "package d\n",
+		"nested/d/more.go": "package d\n",
+	})
+	app := newWalkApp(t, dir)
+	listFiles(t, app)
+
+	tests := []struct {
+		name  string
+		seeds []string
+		depth int
+		want  []string
+	}{
+		{"package only", []string{"a/a.go"}, 0, []string{"a/a.go"}},
+		{"one hop", []string{"a/a.go"}, 1, []string{"a/a.go", "b/b.go"}},
+		{"two hops", []string{"a/a.go"}, 2, []string{"a/a.go", "b/b.go", "c/c.go"}},
+		{"nested module", []string{"nested/n.go"}, 1, []string{"nested/n.go", "nested/d/d.go", "nested/d/more.go"}},
+		{"imported package", []string{"nested/d/d.go"}, 0, []string{"nested/d/d.go", "nested/d/more.go"}},
+		{"several seeds", []string{"b/b.go", "a/a.go"}, 1, []string{"b/b.go", "a/a.go", "c/c.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := app.goPackageFiles(tt.seeds, tt.depth); !slices.Equal(got, tt.want) {
+				t.Errorf("goPackageFiles(%v, %d) = %v, want %v", tt.seeds, tt.depth, got, tt.want)
+			}
+		})
+	}
+}
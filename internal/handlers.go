@@ -1,13 +1,13 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/awesome-gocui/gocui"
 )
 
@@ -48,8 +48,7 @@ func (app *App) SwitchFocus(g *gocui.Gui, v *gocui.View) error {
 func (app *App) FocusContentView(g *gocui.Gui, v *gocui.View) error {
 	// Ensure the target view exists
 	if _, err := g.View(ContentViewName); err != nil {
-		// Content view doesn't exist? Should not happen in normal layout
-		return err
+		return nil // Hidden with toggle_preview
 	}
 
 	// Set focus
@@ -84,6 +83,16 @@ func (app *App) ToggleHelp(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+// ScrollHelpUp scrolls the help up one line.
+func (app *App) ScrollHelpUp(g *gocui.Gui, v *gocui.View) error {
+	return scrollPreview(v, -1)
+}
+
+// ScrollHelpDown scrolls the help down one line.
+func (app *App) ScrollHelpDown(g *gocui.Gui, v *gocui.View) error {
+	return scrollPreview(v, 1)
+}
+
 func (app *App) adjustFilesViewScroll(g *gocui.Gui, v *gocui.View) {
 	// This function remains the same - adjusts FilesView scroll based on app.currentLine
 	currentLine := app.currentLine
@@ -164,15 +173,15 @@ func (app *App) ApplyFilter(g *gocui.Gui, v *gocui.View) error {
 	// --- Update Cache ---
 	if app.cacheFilePath != "" {
 		// Ensure entry exists before modifying
-		if _, ok := app.cache[app.rootDir]; !ok {
-			app.cache[app.rootDir] = DirectoryCache{} // Create if missing
+		if _, ok := app.cache[app.cacheKey]; !ok {
+			app.cache[app.cacheKey] = DirectoryCache{} // Create if missing
 		}
-		currentEntry := app.cache[app.rootDir]
+		currentEntry := app.cache[app.cacheKey]
 		currentEntry.Includes = app.includes
 		currentEntry.Excludes = app.excludes
 		currentEntry.LastOpened = time.Now()
 		currentEntry.FilterMode = app.filterMode
-		app.cache[app.rootDir] = currentEntry
+		app.cache[app.cacheKey] = currentEntry
 
 		err := saveCache(app.cacheFilePath, app.cache)
 		if err != nil {
@@ -259,11 +268,11 @@ func (app *App) ToggleFilterMode(g *gocui.Gui, v *gocui.View) error {
 
 	// Update cache with new mode
 	if app.cacheFilePath != "" {
-		if _, ok := app.cache[app.rootDir]; ok { // Ensure entry exists
-			currentEntry := app.cache[app.rootDir]
+		if _, ok := app.cache[app.cacheKey]; ok { // Ensure entry exists
+			currentEntry := app.cache[app.cacheKey]
 			currentEntry.FilterMode = app.filterMode
 			currentEntry.LastOpened = time.Now() // Update timestamp
-			app.cache[app.rootDir] = currentEntry
+			app.cache[app.cacheKey] = currentEntry
 			err := saveCache(app.cacheFilePath, app.cache)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on ToggleFilterMode: %v\n", err)
@@ -297,8 +306,17 @@ func (app *App) ToggleSelect(g *gocui.Gui, v *gocui.View) error {
 		return nil // No file selected or list empty
 	}
 	selectedFile := app.fileList[app.currentLine]
+	confirmFile := app.pendingSensitive
+	app.pendingSensitive = ""
 	if app.selectedFiles[selectedFile] {
 		delete(app.selectedFiles, selectedFile)
+		delete(app.lineRanges, selectedFile) // Deselecting drops any partial selection
+	} else if isSensitivePath(selectedFile) && confirmFile != selectedFile {
+		// Files like .env and *.pem need a second Space to be included.
+		app.pendingSensitive = selectedFile
+		app.mutex.Unlock()
+		app.updateStatus(g, fmt.Sprintf("%s may contain secrets. Press %s again to include it.", selectedFile, app.keyHint(FilesViewName, "toggle_select")))
+		return nil
 	} else {
 		// Optional: Check against MaxSelectedFiles limit?
 		// if len(app.selectedFiles) >= MaxSelectedFiles {
@@ -308,6 +326,9 @@ func (app *App) ToggleSelect(g *gocui.Gui, v *gocui.View) error {
 		//     return nil
 		// }
 		app.selectedFiles[selectedFile] = true
+		if confirmFile == selectedFile {
+			app.statusMessage = "" // Drop the confirmation prompt
+		}
 	}
 	app.mutex.Unlock()
 
@@ -329,16 +350,13 @@ func (app *App) SelectAllFiles(g *gocui.Gui, v *gocui.View) error {
 		return nil
 	}
 
-	// Check if all *currently visible* files are selected
+	// Check if all *currently visible* files are selected. Sensitive files are
+	// never selected in bulk, so they don't count.
 	allVisibleSelected := true
-	if len(app.selectedFiles) < len(app.fileList) { // Optimization: if counts differ, not all selected
-		allVisibleSelected = false
-	} else {
-		for _, file := range app.fileList {
-			if !app.selectedFiles[file] {
-				allVisibleSelected = false
-				break
-			}
+	for _, file := range app.fileList {
+		if !app.selectedFiles[file] && !isSensitivePath(file) {
+			allVisibleSelected = false
+			break
 		}
 	}
 
@@ -347,16 +365,25 @@ func (app *App) SelectAllFiles(g *gocui.Gui, v *gocui.View) error {
 		// Deselect all visible files
 		for _, file := range app.fileList {
 			delete(app.selectedFiles, file)
+			delete(app.lineRanges, file)
 		}
 		statusMsg = "Deselected all visible files."
 	} else {
 		// Select all visible files
 		// Optional: Check limit before selecting all
 		// if len(app.fileList) > MaxSelectedFiles { ... handle limit ... }
+		sensitive := 0
 		for _, file := range app.fileList {
+			if isSensitivePath(file) && !app.selectedFiles[file] {
+				sensitive++ // Needs explicit confirmation (see ToggleSelect)
+				continue
+			}
 			app.selectedFiles[file] = true
 		}
 		statusMsg = "Selected all visible files."
+		if sensitive > 0 {
+			statusMsg = fmt.Sprintf("Selected all visible files except %d sensitive file(s) [!]; select those with %s.", sensitive, app.keyHint(FilesViewName, "toggle_select"))
+		}
 	}
 	app.mutex.Unlock()
 
@@ -379,7 +406,7 @@ func (app *App) SelectAllFiles(g *gocui.Gui, v *gocui.View) error {
 }
 
 func (app *App) CopyAllSelected(g *gocui.Gui, v *gocui.View) error {
-	// This function remains the same - copies selected files, highlights file list
+	// Copies selected files (see buildBundle), highlights file list
 	app.mutex.Lock()
 
 	if len(app.selectedFiles) == 0 {
@@ -398,52 +425,57 @@ func (app *App) CopyAllSelected(g *gocui.Gui, v *gocui.View) error {
 		return nil
 	}
 
-	selectedFileCopy := make(map[string]bool, len(app.selectedFiles))
-	for k, v := range app.selectedFiles {
-		selectedFileCopy[k] = v
-	}
-	fileListCopy := make([]string, len(app.fileList))
-	copy(fileListCopy, app.fileList)
-	rootDirCopy := app.rootDir
+	selection := app.selectionOrder()
+	key := app.bundleStateKey()
 
 	app.mutex.Unlock()
 
-	var contentBuilder strings.Builder
-	count := 0
+	selectedPaths := app.inBundleOrder(selection)
 
-	for _, relPath := range fileListCopy {
-		if selectedFileCopy[relPath] {
-			fullPath := filepath.Join(rootDirCopy, relPath)
-			fileContent, err := os.ReadFile(fullPath)
-			separator := fmt.Sprintf("==========================\nFILE: %s\n==========================\n", relPath)
+	result := app.buildBundle(selectedPaths)
+	parts := app.splitBundle(result)
 
-			contentBuilder.WriteString(separator)
-			if err != nil {
-				contentBuilder.WriteString(fmt.Sprintf("\n!!! ERROR READING FILE: %v !!!\n\n", err))
-			} else {
-				contentBuilder.WriteString("\n")
-				contentBuilder.WriteString(string(fileContent))
-				if !strings.HasSuffix(string(fileContent), "\n") {
-					contentBuilder.WriteString("\n")
-				}
-				contentBuilder.WriteString("\n")
-			}
-			count++
-		}
+	app.mutex.Lock()
+	app.bundleParts = parts
+	app.bundlePart = 0
+	app.partsKey = key
+	app.mutex.Unlock()
+
+	if parts != nil {
+		return app.copyBundlePart(g, 0)
 	}
+	return app.copyText(g, app.withInstruction(result.content), result, fmt.Sprintf("content of %d file(s)", result.files))
+}
 
-	content := contentBuilder.String()
-	err := clipboard.WriteAll(content)
+// copyText puts text, built from result, on the clipboard, flashes the copied
+// files and reports what was copied (and any redactions) in the status bar.
+func (app *App) copyText(g *gocui.Gui, text string, result bundle, what string) error {
+	app.mutex.Lock()
+	chain := app.clipboards
+	app.mutex.Unlock()
+	used, failures := copyWithFallback(chain, text)
 
 	var statusMsg string
-	if err != nil {
-		statusMsg = "Error copying to clipboard!"
+	var err error
+	if len(used) == 0 {
+		err = errors.Join(failures...)
+		statusMsg = fmt.Sprintf("Error copying to clipboard: %v", strings.ReplaceAll(err.Error(), "\n", "; "))
 	} else {
-		statusMsg = fmt.Sprintf("Copied content of %d file(s) to clipboard.", count)
+		statusMsg = fmt.Sprintf("Copied %s via %s.", what, usedSinkNames(used))
+		if len(failures) > 0 {
+			statusMsg = fmt.Sprintf("Copied %s via %s (%s failed).", what, usedSinkNames(used), failedSinkNames(chain, used))
+		}
+		if len(result.redacted) > 0 {
+			statusMsg += fmt.Sprintf(" WARNING: secrets redacted in %s", strings.Join(result.redacted, ", "))
+		}
+	}
+	resetAfter := 3 * time.Second
+	if len(result.redacted) > 0 {
+		resetAfter = 8 * time.Second // Long enough to read the file list
 	}
 
 	// --- File List Highlight ---
-	if err == nil && count > 0 {
+	if err == nil && result.files > 0 {
 		app.mutex.Lock()
 		app.isCopyHighlightActive = true
 		app.mutex.Unlock()
@@ -466,6 +498,59 @@ func (app *App) CopyAllSelected(g *gocui.Gui, v *gocui.View) error {
 
 	app.updateStatus(g, statusMsg)
 
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(resetAfter)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// ToggleLineNumbers turns line numbers in the copied bundle (and the content
+// preview) on or off, remembering the choice for this directory.
+func (app *App) ToggleLineNumbers(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.lineNumbers = !app.lineNumbers
+	enabled := app.lineNumbers
+
+	// --- Update Cache ---
+	if app.cacheFilePath != "" {
+		err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+			entry.LineNumbers = enabled
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on ToggleLineNumbers: %v\n", err)
+		}
+	}
+	app.mutex.Unlock()
+
+	app.refreshContentView(g)
+
+	statusMsg := "Line numbers disabled."
+	if enabled {
+		statusMsg = "Line numbers enabled."
+	}
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(2 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
 	return nil
 }
 
@@ -541,6 +626,9 @@ func (app *App) ScrollContentLineUp(g *gocui.Gui, v *gocui.View) error {
 	if cv := g.CurrentView(); cv == nil || cv.Name() != ContentViewName {
 		return nil
 	}
+	if app.isVisualActive() {
+		return app.moveVisualCursor(g, -1) // Extend the range being marked
+	}
 	return app.scrollContent(g, -1) // Scroll up by 1 line
 }
 
@@ -550,5 +638,37 @@ func (app *App) ScrollContentLineDown(g *gocui.Gui, v *gocui.View) error {
 	if cv := g.CurrentView(); cv == nil || cv.Name() != ContentViewName {
 		return nil
 	}
+	if app.isVisualActive() {
+		return app.moveVisualCursor(g, 1) // Extend the range being marked
+	}
 	return app.scrollContent(g, 1) // Scroll down by 1 line
 }
+
+// usedSinkNames lists the sinks that took the text, flagging those that
+// cannot confirm delivery.
+func usedSinkNames(used []ClipboardBackend) string {
+	var names []string
+	for _, backend := range used {
+		if isUnverified(backend) {
+			names = append(names, backend.Name()+" (delivery unverified)")
+		} else {
+			names = append(names, backend.Name())
+		}
+	}
+	return strings.Join(names, " and ")
+}
+
+// failedSinkNames lists the sinks in chain tried before the last of used that
+// failed.
+func failedSinkNames(chain []ClipboardBackend, used []ClipboardBackend) string {
+	var names []string
+	for _, backend := range chain {
+		if backend == used[len(used)-1] {
+			break
+		}
+		if !slices.Contains(used, backend) {
+			names = append(names, backend.Name())
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -67,6 +67,7 @@ func (app *App) ShowCacheView(g *gocui.Gui, v *gocui.View) error {
 func (app *App) CloseCacheView(g *gocui.Gui, v *gocui.View) error {
 	app.mutex.Lock()
 	app.showCacheView = false
+	app.statusMessage = ""                     // Don't carry cache view messages back
 	app.cacheViewContent = ""                  // Clear content
 	app.awaitingCacheClearConfirmation = false // Reset confirmation state
 	app.mutex.Unlock()
@@ -102,7 +103,8 @@ func (app *App) PromptClearCache(g *gocui.Gui, v *gocui.View) error {
 	app.awaitingCacheClearConfirmation = true
 	app.mutex.Unlock()
 
-	app.updateStatus(g, "CLEAR CACHE? (y/n) - Press 'y' to confirm, 'n' or Esc to cancel.")
+	app.updateStatus(g, fmt.Sprintf("CLEAR CACHE? - Press %s to confirm, %s to cancel.",
+		app.keyHint(CacheViewName, "confirm_clear"), app.keyHints(CacheViewName, "cancel_clear", "close")))
 	return nil
 }
 
@@ -136,11 +138,12 @@ func (app *App) ConfirmClearCache(g *gocui.Gui, v *gocui.View) error {
 			app.mutex.Lock()
 			app.cache = make(AppCache)
 			// Re-add entry for the current directory with current settings
-			app.cache[app.rootDir] = DirectoryCache{
-				Includes:   app.includes,
-				Excludes:   app.excludes,
-				LastOpened: time.Now(),
-				FilterMode: app.filterMode,
+			app.cache[app.cacheKey] = DirectoryCache{
+				Includes:      app.includes,
+				Excludes:      app.excludes,
+				LastOpened:    time.Now(),
+				FilterMode:    app.filterMode,
+				ForceIncludes: app.forceIncludeList(),
 			}
 			// No need to save here, as the file is gone. It will be recreated on next save.
 			app.mutex.Unlock()
@@ -200,6 +203,17 @@ func (app *App) CancelClearCache(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+// updateDirectoryCache applies update to the cache entry for the current directory
+// (creating it if missing), refreshes its LastOpened time and saves the cache file.
+// Assumes mutex is held.
+func (app *App) updateDirectoryCache(update func(entry *DirectoryCache)) error {
+	entry := app.cache[app.cacheKey]
+	update(&entry)
+	entry.LastOpened = time.Now()
+	app.cache[app.cacheKey] = entry
+	return saveCache(app.cacheFilePath, app.cache)
+}
+
 // scrollCacheView handles scrolling within the cache view.
 func (app *App) scrollCacheView(g *gocui.Gui, v *gocui.View, direction int) error {
 	if v == nil || v.Name() != CacheViewName {
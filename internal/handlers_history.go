@@ -0,0 +1,307 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ExportBundle writes the selected files' bundle, with metadata, to a new
+// file in the history directory.
+func (app *App) ExportBundle(g *gocui.Gui, v *gocui.View) error {
+	if !app.hasSelection(g) {
+		return nil
+	}
+	return app.exportTo(g, "")
+}
+
+// PromptExportPath asks for the path to export the bundle to, prefilled with
+// the file name it would get in the history directory.
+func (app *App) PromptExportPath(g *gocui.Gui, v *gocui.View) error {
+	if !app.hasSelection(g) {
+		return nil
+	}
+
+	initial := historyFileName(time.Now(), app.RootDirs())
+	if historyDir, err := getHistoryDir(); err == nil {
+		initial = filepath.Join(historyDir, initial)
+	}
+
+	app.mutex.Lock()
+	app.showExportPrompt = true
+	app.exportPromptText = initial
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the prompt
+}
+
+// ConfirmExportPath exports to the path typed in the prompt.
+func (app *App) ConfirmExportPath(g *gocui.Gui, v *gocui.View) error {
+	path := strings.TrimSpace(v.Buffer())
+	if err := app.CloseExportPrompt(g, v); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return app.exportTo(g, path)
+}
+
+// CloseExportPrompt closes the export path prompt.
+func (app *App) CloseExportPrompt(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showExportPrompt = false
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(ExportPromptViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// exportTo exports the bundle (see exportBundle) and reports the result.
+func (app *App) exportTo(g *gocui.Gui, path string) error {
+	written, result, err := app.exportBundle(path)
+
+	var statusMsg string
+	if err != nil {
+		statusMsg = fmt.Sprintf("Error exporting bundle: %v", err)
+	} else {
+		statusMsg = fmt.Sprintf("Exported %d file(s) to %s.", result.files, written)
+		if len(result.redacted) > 0 {
+			statusMsg += fmt.Sprintf(" WARNING: secrets redacted in %s", strings.Join(result.redacted, ", "))
+		}
+	}
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(5 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// hasSelection reports whether files are selected, telling the user if not.
+func (app *App) hasSelection(g *gocui.Gui) bool {
+	app.mutex.Lock()
+	selected := len(app.selectedFiles) > 0
+	app.mutex.Unlock()
+	if selected {
+		return true
+	}
+
+	app.updateStatus(g, "No files selected to copy.")
+	go func() {
+		time.Sleep(2 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), "No files") {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}()
+	return false
+}
+
+// ShowHistoryView opens the browser of exported bundles.
+func (app *App) ShowHistoryView(g *gocui.Gui, v *gocui.View) error {
+	entries, err := listHistory()
+	if err != nil {
+		app.updateStatus(g, fmt.Sprintf("Error reading history: %v", err))
+		return nil
+	}
+
+	app.mutex.Lock()
+	app.historyEntries = entries
+	app.historyCursor = 0
+	app.historyDiff = ""
+	app.showHistory = true
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the browser
+}
+
+// CloseHistoryView leaves a diff for the list, or closes the browser.
+func (app *App) CloseHistoryView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	if app.historyDiff != "" {
+		app.historyDiff = ""
+		app.mutex.Unlock()
+		app.renderHistoryView(g)
+		return nil
+	}
+	app.showHistory = false
+	app.historyEntries = nil
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(HistoryViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// HistoryCursorUp moves the list cursor up, or scrolls a diff.
+func (app *App) HistoryCursorUp(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	inDiff := app.historyDiff != ""
+	if !inDiff {
+		app.historyCursor = max(0, app.historyCursor-1)
+	}
+	app.mutex.Unlock()
+
+	if inDiff {
+		if ox, oy := v.Origin(); oy > 0 {
+			return v.SetOrigin(ox, oy-1)
+		}
+		return nil
+	}
+	app.renderHistoryView(g)
+	return nil
+}
+
+// HistoryCursorDown moves the list cursor down, or scrolls a diff.
+func (app *App) HistoryCursorDown(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	inDiff := app.historyDiff != ""
+	if !inDiff {
+		app.historyCursor = max(0, min(len(app.historyEntries)-1, app.historyCursor+1))
+	}
+	app.mutex.Unlock()
+
+	if inDiff {
+		ox, oy := v.Origin()
+		if _, vy := v.Size(); oy+vy < len(v.BufferLines())-1 {
+			return v.SetOrigin(ox, oy+1)
+		}
+		return nil
+	}
+	app.renderHistoryView(g)
+	return nil
+}
+
+// selectedHistoryEntry returns the entry under the list cursor.
+func (app *App) selectedHistoryEntry() (historyEntry, bool) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.historyCursor < 0 || app.historyCursor >= len(app.historyEntries) {
+		return historyEntry{}, false
+	}
+	return app.historyEntries[app.historyCursor], true
+}
+
+// RecopyHistoryEntry copies the bundle under the cursor again, exactly as
+// it was exported.
+func (app *App) RecopyHistoryEntry(g *gocui.Gui, v *gocui.View) error {
+	entry, ok := app.selectedHistoryEntry()
+	if !ok {
+		return nil
+	}
+	meta, body, err := readHistoryFile(entry.path, true)
+	if err != nil {
+		app.updateStatus(g, fmt.Sprintf("Error reading %s: %v", filepath.Base(entry.path), err))
+		return nil
+	}
+
+	app.mutex.Lock()
+	app.historyDiff = ""
+	app.mutex.Unlock()
+	if err := app.CloseHistoryView(g, v); err != nil {
+		return err
+	}
+	// Not a copy of the current selection, so no file list highlight.
+	return app.copyText(g, body, bundle{}, fmt.Sprintf("exported bundle of %d file(s)", len(meta.Files)))
+}
+
+// DiffHistoryEntry shows how the files of the bundle under the cursor
+// changed since it was exported.
+func (app *App) DiffHistoryEntry(g *gocui.Gui, v *gocui.View) error {
+	entry, ok := app.selectedHistoryEntry()
+	if !ok {
+		return nil
+	}
+	diff, err := app.diffHistory(entry)
+	if err != nil {
+		diff = fmt.Sprintf("Cannot compare %s: %v\n", filepath.Base(entry.path), err)
+	}
+
+	app.mutex.Lock()
+	app.historyDiff = diff
+	app.mutex.Unlock()
+	app.renderHistoryView(g)
+	return nil
+}
+
+// renderHistoryView redraws the list of exports or the diff being shown.
+func (app *App) renderHistoryView(g *gocui.Gui) {
+	v, err := g.View(HistoryViewName)
+	if err != nil {
+		return
+	}
+
+	app.mutex.Lock()
+	entries := app.historyEntries
+	cursor := app.historyCursor
+	diff := app.historyDiff
+	app.mutex.Unlock()
+
+	v.Clear()
+	_ = v.SetOrigin(0, 0)
+	if diff != "" {
+		v.Title = fmt.Sprintf(" Changes since export (%s: Scroll, %s: Back) ",
+			app.keyHints(HistoryViewName, "cursor_down", "cursor_up"), app.keyHint(HistoryViewName, "close"))
+		v.Highlight = false
+		fmt.Fprint(v, diff)
+		return
+	}
+
+	v.Title = fmt.Sprintf(" History (%s: Copy again, %s: Diff with current files, %s: Close) ",
+		app.keyHint(HistoryViewName, "confirm"), app.keyHint(HistoryViewName, "diff"), app.keyHint(HistoryViewName, "close"))
+	v.Highlight = true
+	if len(entries) == 0 {
+		v.Highlight = false
+		historyDir, _ := getHistoryDir()
+		fmt.Fprintf(v, "No exported bundles yet. Press %s in the Files view to export to\n  %s\n", app.keyHint(FilesViewName, "export"), historyDir)
+		return
+	}
+	for _, entry := range entries {
+		name := filepath.Base(entry.path)
+		if entry.err != nil {
+			fmt.Fprintf(v, "%s (unreadable: %v)\n", name, entry.err)
+			continue
+		}
+		meta := entry.meta
+		tokens := "n/a"
+		if meta.Tokens > 0 {
+			tokens = fmt.Sprintf("%d", meta.Tokens)
+		}
+		commit := ""
+		if meta.Commit != "" {
+			commit = fmt.Sprintf("  %.7s", meta.Commit)
+		}
+		repo := ""
+		if len(meta.Roots) > 0 {
+			repo = filepath.Base(meta.Roots[0])
+		}
+		fmt.Fprintf(v, "%s  %-20s %4d file(s)  %8s tokens%s\n",
+			meta.Created.Local().Format("2006-01-02 15:04"), repo, len(meta.Files), tokens, commit)
+	}
+	_ = v.SetCursor(0, cursor)
+	if _, height := v.Size(); cursor >= height {
+		_ = v.SetOrigin(0, cursor-height+1)
+		_ = v.SetCursor(0, height-1)
+	}
+}
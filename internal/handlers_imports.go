@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ExpandGoPackage selects every file of the Go packages of the selected files
+// (or the file under the cursor when nothing is selected).
+func (app *App) ExpandGoPackage(g *gocui.Gui, v *gocui.View) error {
+	return app.expandGoImports(g, 0)
+}
+
+// ExpandGoImports is ExpandGoPackage plus the in-module packages they import,
+// followed to the configured import depth.
+func (app *App) ExpandGoImports(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	depth := app.importDepth
+	app.mutex.Unlock()
+	return app.expandGoImports(g, depth)
+}
+
+func (app *App) expandGoImports(g *gocui.Gui, depth int) error {
+	app.mutex.Lock()
+	var seeds []string
+	for relPath := range app.selectedFiles {
+		if strings.HasSuffix(relPath, ".go") {
+			seeds = append(seeds, relPath)
+		}
+	}
+	if len(seeds) == 0 && app.currentLine >= 0 && app.currentLine < len(app.fileList) {
+		if relPath := app.fileList[app.currentLine]; strings.HasSuffix(relPath, ".go") {
+			seeds = append(seeds, relPath)
+		}
+	}
+	sort.Strings(seeds) // Stable traversal order
+
+	var added []string
+	for _, relPath := range app.goPackageFiles(seeds, depth) {
+		if !app.selectedFiles[relPath] {
+			app.selectedFiles[relPath] = true
+			added = append(added, relPath)
+		}
+	}
+	tokenizer := app.tokenizer
+	app.mutex.Unlock()
+
+	var statusMsg string
+	switch {
+	case len(seeds) == 0:
+		statusMsg = "Select a Go file (or move the cursor to one) first."
+	case len(added) == 0:
+		statusMsg = "Nothing to add: related Go files are already selected."
+	default:
+		scope := "same package"
+		if depth > 0 {
+			scope = fmt.Sprintf("package + imports, depth %d", depth)
+		}
+		statusMsg = fmt.Sprintf("Added %d file(s) (%s).", len(added), scope)
+		if tokenizer != nil {
+			_, tokens, _ := app.bundleSize(added)
+			statusMsg = fmt.Sprintf("Added %d file(s), +%d tokens (%s).", len(added), tokens, scope)
+		}
+	}
+
+	app.refreshFilesView(g)
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(3 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
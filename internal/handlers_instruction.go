@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ShowInstructionView opens the multi-line instruction editor.
+func (app *App) ShowInstructionView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showInstruction = true
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the editor
+}
+
+// CloseInstructionView saves the edited instruction, remembering it for this
+// directory, and closes the editor.
+func (app *App) CloseInstructionView(g *gocui.Gui, v *gocui.View) error {
+	instruction := strings.TrimSpace(v.Buffer())
+
+	app.mutex.Lock()
+	app.instruction = instruction
+	app.showInstruction = false
+	after := app.instructionAfter
+
+	// --- Update Cache ---
+	if app.cacheFilePath != "" {
+		err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+			entry.Instruction = instruction
+			entry.InstructionAfter = after
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on CloseInstructionView: %v\n", err)
+		}
+	}
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(InstructionViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	app.renderStatus(g) // Token total includes the instruction
+	return err
+}
+
+// ToggleInstructionPosition switches between prepending and appending the
+// instruction to the bundle.
+func (app *App) ToggleInstructionPosition(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.instructionAfter = !app.instructionAfter
+	app.mutex.Unlock()
+
+	v.Title = app.instructionViewTitle()
+	return nil
+}
+
+// instructionViewTitle describes where the instruction goes and how to close.
+func (app *App) instructionViewTitle() string {
+	app.mutex.Lock()
+	after := app.instructionAfter
+	app.mutex.Unlock()
+
+	position := "before"
+	if after {
+		position = "after"
+	}
+	return fmt.Sprintf(" Instruction, placed %s the files (%s: Move, %s: Save & Close) ", position,
+		app.keyHint(InstructionViewName, "toggle_instruction_position"), app.keyHint(InstructionViewName, "close"))
+}
+
+// withInstruction places the instruction before or after bundle text.
+func (app *App) withInstruction(text string) string {
+	app.mutex.Lock()
+	instruction := app.instruction
+	after := app.instructionAfter
+	app.mutex.Unlock()
+
+	if instruction == "" {
+		return text
+	}
+	if after {
+		return text + instruction + "\n"
+	}
+	return instruction + "\n\n" + text
+}
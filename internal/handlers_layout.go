@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// GrowFilesPane widens the Files pane, or makes it taller when stacked.
+func (app *App) GrowFilesPane(g *gocui.Gui, v *gocui.View) error {
+	return app.resizeFilesPane(g, 1)
+}
+
+// ShrinkFilesPane narrows the Files pane, or makes it shorter when stacked.
+func (app *App) ShrinkFilesPane(g *gocui.Gui, v *gocui.View) error {
+	return app.resizeFilesPane(g, -1)
+}
+
+// resizeFilesPane moves the border between the Files and Content panes by
+// one step in direction. The layout is saved once the resizing pauses.
+func (app *App) resizeFilesPane(g *gocui.Gui, direction int) error {
+	maxX, maxY := g.Size()
+
+	app.mutex.Lock()
+	if app.layout.HidePreview {
+		app.mutex.Unlock()
+		app.flashLayoutStatus(g, fmt.Sprintf("The preview is hidden (%s: Show it).", app.keyHint(FilesViewName, "toggle_preview")))
+		return nil
+	}
+	if app.layout.stacks(maxX, app.stackWidth) {
+		rows := stackedFilesRows(app.layout.FilesRows, maxY)
+		app.layout.FilesRows = stackedFilesRows(rows+direction*paneRowsStep, maxY)
+	} else {
+		width := sideFilesWidth(app.layout.FilesWidth, maxX)
+		app.layout.FilesWidth = sideFilesWidth(width+direction*paneWidthStep, maxX)
+	}
+	app.scheduleLayoutSave()
+	app.mutex.Unlock()
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// TogglePreview hides or shows the Content view. While it is hidden the
+// Files pane takes the whole width. Hiding it abandons a range being marked.
+func (app *App) TogglePreview(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.layout.HidePreview = !app.layout.HidePreview
+	hidden := app.layout.HidePreview
+	if hidden {
+		app.visualActive = false
+	}
+	app.saveLayout()
+	app.mutex.Unlock()
+
+	if hidden {
+		if cv := g.CurrentView(); cv != nil && cv.Name() == ContentViewName {
+			if _, err := g.SetCurrentView(FilesViewName); err != nil {
+				return err
+			}
+		}
+		_ = g.DeleteView(ContentViewName)
+		app.flashLayoutStatus(g, fmt.Sprintf("Preview hidden (%s: Show it).", app.keyHint(FilesViewName, "toggle_preview")))
+	} else {
+		app.flashLayoutStatus(g, "Preview shown.")
+	}
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// CycleLayout switches between the auto, side-by-side and stacked layouts.
+func (app *App) CycleLayout(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	next := layoutModes[0]
+	for i, mode := range layoutModes {
+		if mode == app.layout.Mode {
+			next = layoutModes[(i+1)%len(layoutModes)]
+		}
+	}
+	app.layout.Mode = next
+	stackWidth := app.stackWidth
+	app.saveLayout()
+	app.mutex.Unlock()
+
+	switch next {
+	case LayoutSide:
+		app.flashLayoutStatus(g, "Layout: side by side.")
+	case LayoutStacked:
+		app.flashLayoutStatus(g, "Layout: stacked.")
+	default:
+		app.flashLayoutStatus(g, fmt.Sprintf("Layout: auto (stacked below %d columns).", stackWidth))
+	}
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// flashLayoutStatus shows msg in the status bar for a few seconds.
+func (app *App) flashLayoutStatus(g *gocui.Gui, msg string) {
+	app.updateStatus(g, msg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(3 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(msg)
+}
+
+// layoutSaveDelay is how long resizing has to pause before the layout is
+// saved, so holding < or > doesn't rewrite the cache on every step.
+const layoutSaveDelay = time.Second
+
+// scheduleLayoutSave saves the layout once no resize happened for
+// layoutSaveDelay. Assumes mutex is held.
+func (app *App) scheduleLayoutSave() {
+	if app.layoutSaveTimer != nil {
+		app.layoutSaveTimer.Stop()
+	}
+	app.layoutSaveTimer = time.AfterFunc(layoutSaveDelay, func() {
+		app.mutex.Lock()
+		defer app.mutex.Unlock()
+		app.layoutSaveTimer = nil
+		app.saveLayout()
+	})
+}
+
+// FlushLayout saves a resize still waiting for scheduleLayoutSave, so one
+// made just before quitting is kept.
+func (app *App) FlushLayout() {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.layoutSaveTimer != nil {
+		app.layoutSaveTimer.Stop()
+		app.layoutSaveTimer = nil
+		app.saveLayout()
+	}
+}
+
+// saveLayout persists the pane layout. Assumes mutex is held.
+func (app *App) saveLayout() {
+	if app.cacheFilePath == "" {
+		return
+	}
+	layout := app.layout
+	err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+		entry.Layout = &layout
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on saveLayout: %v\n", err)
+	}
+}
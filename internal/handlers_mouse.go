@@ -0,0 +1,220 @@
+package internal
+
+import "github.com/awesome-gocui/gocui"
+
+const (
+	// mouseMotion is the key gocui reports mouse moves and drags as. Ctrl+Space
+	// arrives as the same key, so handlers must check that a drag is going on.
+	mouseMotion = gocui.Key(0)
+	// mouseWheelLines is how far one wheel step scrolls text.
+	mouseWheelLines = 3
+)
+
+// setMouseBindings binds clicks, the wheel and divider drags. Clicks are
+// delivered to the view under the pointer, whether or not it has focus.
+func (app *App) setMouseBindings(g *gocui.Gui) error {
+	bindings := []struct {
+		view    string
+		key     gocui.Key
+		handler func(*gocui.Gui, *gocui.View) error
+	}{
+		{FilesViewName, gocui.MouseLeft, app.ClickFilesView},
+		{FilesViewName, gocui.MouseWheelUp, app.CursorUpMouse},
+		{FilesViewName, gocui.MouseWheelDown, app.CursorDownMouse},
+		{FilterViewName, gocui.MouseLeft, app.ClickFilterView},
+		{ContentViewName, gocui.MouseLeft, app.ClickContentView},
+		{ContentViewName, gocui.MouseWheelUp, app.WheelContentUp},
+		{ContentViewName, gocui.MouseWheelDown, app.WheelContentDown},
+		{CacheViewName, gocui.MouseWheelUp, app.WheelCacheUp},
+		{CacheViewName, gocui.MouseWheelDown, app.WheelCacheDown},
+		{DividerViewName, gocui.MouseLeft, app.StartDividerDrag},
+		{"", mouseMotion, app.DragDivider},
+		{"", gocui.MouseRelease, app.EndDividerDrag},
+	}
+	for _, b := range bindings {
+		if err := g.SetKeybinding(b.view, b.key, gocui.ModNone, b.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mainViewFocused reports whether focus is on one of the main views, so
+// clicks on them do not reach behind an open overlay.
+func mainViewFocused(g *gocui.Gui) bool {
+	v := g.CurrentView()
+	if v == nil {
+		return true
+	}
+	switch v.Name() {
+	case FilesViewName, FilterViewName, ContentViewName:
+		return true
+	}
+	return false
+}
+
+// focusView moves focus to the named main view.
+func (app *App) focusView(g *gocui.Gui, name string) error {
+	if _, err := g.SetCurrentView(name); err != nil {
+		return err
+	}
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// ClickFilesView focuses the Files view and moves the cursor to the clicked
+// file. A click on its checkbox also toggles the selection.
+func (app *App) ClickFilesView(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	if err := app.focusView(g, FilesViewName); err != nil {
+		return err
+	}
+
+	mx, my := g.MousePosition()
+	x0, y0, _, _, err := g.ViewPosition(FilesViewName)
+	if err != nil {
+		return nil
+	}
+	ox, oy := v.Origin()
+	line, column := oy+my-y0-1, ox+mx-x0-1
+
+	app.mutex.Lock()
+	if line < 0 || line >= len(app.fileList) {
+		app.mutex.Unlock()
+		return nil // Below the last file
+	}
+	app.currentLine = line
+	app.mutex.Unlock()
+	app.refreshFilesView(g)
+	app.refreshContentView(g)
+
+	if column < len("[ ]") {
+		return app.ToggleSelect(g, v)
+	}
+	return nil
+}
+
+// ClickFilterView focuses the Filter view for editing.
+func (app *App) ClickFilterView(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	return app.focusView(g, FilterViewName)
+}
+
+// ClickContentView focuses the Content view.
+func (app *App) ClickContentView(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	return app.focusView(g, ContentViewName)
+}
+
+// CursorUpMouse moves the Files view cursor up one file per wheel step.
+func (app *App) CursorUpMouse(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	return app.CursorUp(g, v)
+}
+
+// CursorDownMouse moves the Files view cursor down one file per wheel step.
+func (app *App) CursorDownMouse(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	return app.CursorDown(g, v)
+}
+
+// WheelContentUp scrolls the Content view up.
+func (app *App) WheelContentUp(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	return app.scrollContent(g, -mouseWheelLines)
+}
+
+// WheelContentDown scrolls the Content view down.
+func (app *App) WheelContentDown(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	return app.scrollContent(g, mouseWheelLines)
+}
+
+// WheelCacheUp scrolls the cache view up.
+func (app *App) WheelCacheUp(g *gocui.Gui, v *gocui.View) error {
+	for range mouseWheelLines {
+		if err := app.scrollCacheView(g, v, -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WheelCacheDown scrolls the cache view down.
+func (app *App) WheelCacheDown(g *gocui.Gui, v *gocui.View) error {
+	for range mouseWheelLines {
+		if err := app.scrollCacheView(g, v, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartDividerDrag starts resizing the panes from the divider between the
+// Files and Content views.
+func (app *App) StartDividerDrag(g *gocui.Gui, v *gocui.View) error {
+	if !mainViewFocused(g) {
+		return nil
+	}
+	mx, _ := g.MousePosition()
+	app.mutex.Lock()
+	app.draggingDivider = true
+	app.dividerColumn = mx
+	app.mutex.Unlock()
+	return nil
+}
+
+// DragDivider moves the divider with the pointer while it is dragged.
+// Anything else sent as mouseMotion, such as Ctrl+Space, is ignored.
+func (app *App) DragDivider(g *gocui.Gui, v *gocui.View) error {
+	app.moveDivider(g)
+	return nil
+}
+
+// EndDividerDrag drops the divider where the button is released and saves
+// the new width.
+func (app *App) EndDividerDrag(g *gocui.Gui, v *gocui.View) error {
+	if app.moveDivider(g) {
+		app.mutex.Lock()
+		app.draggingDivider = false
+		app.saveLayout()
+		app.mutex.Unlock()
+	}
+	return nil
+}
+
+// moveDivider sets the Files pane width to the pointer's column if the
+// divider is being dragged, and reports whether it was. Events that leave the
+// pointer in its column are skipped: a key sent as mouseMotion does not move
+// the pointer, and a vertical drag does not change the width.
+func (app *App) moveDivider(g *gocui.Gui) bool {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if !app.draggingDivider {
+		return false
+	}
+	mx, _ := g.MousePosition()
+	if mx == app.dividerColumn {
+		return true
+	}
+	app.dividerColumn = mx
+	maxX, _ := g.Size()
+	app.layout.FilesWidth = sideFilesWidth(mx, maxX)
+	return true
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// newTestGui returns a Gui on a simulated screen, closed when the test ends.
+func newTestGui(t *testing.T) *gocui.Gui {
+	t.Helper()
+	g, err := gocui.NewGui(gocui.OutputSimulator, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(g.Close)
+	return g
+}
+
+// TestMouseBindings checks that the mouse bindings register and that clicks
+// only reach the main views while no overlay has focus.
+func TestMouseBindings(t *testing.T) {
+	g := newTestGui(t)
+	app := newTestApp(t, nil)
+	if err := app.setMouseBindings(g); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{FilesViewName, ContentViewName, HelpViewName} {
+		if _, err := g.SetView(name, 0, 0, 10, 5, 0); err != nil && err != gocui.ErrUnknownView {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		focus string
+		want  bool
+	}{
+		{"", true},
+		{FilesViewName, true},
+		{ContentViewName, true},
+		{HelpViewName, false},
+	}
+	for _, tt := range tests {
+		if tt.focus != "" {
+			if _, err := g.SetCurrentView(tt.focus); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := mainViewFocused(g); got != tt.want {
+			t.Errorf("mainViewFocused with %q focused = %v, want %v", tt.focus, got, tt.want)
+		}
+	}
+}
+
+// TestDividerNeedsDrag checks that motion events, which Ctrl+Space is
+// reported as, leave the layout alone unless the divider is being dragged.
+func TestDividerNeedsDrag(t *testing.T) {
+	g := newTestGui(t)
+	app := newTestApp(t, nil)
+	app.layout.FilesWidth = 40
+
+	if err := app.DragDivider(g, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.EndDividerDrag(g, nil); err != nil {
+		t.Fatal(err)
+	}
+	if app.layout.FilesWidth != 40 || app.draggingDivider {
+		t.Errorf("layout = %+v, dragging = %v after motion without a drag", app.layout, app.draggingDivider)
+	}
+
+	// A drag event in the pointer's last column does not move the divider,
+	// but the release still ends the drag.
+	mx, _ := g.MousePosition()
+	app.draggingDivider, app.dividerColumn = true, mx
+	if err := app.EndDividerDrag(g, nil); err != nil {
+		t.Fatal(err)
+	}
+	if app.layout.FilesWidth != 40 || app.draggingDivider {
+		t.Errorf("layout = %+v, dragging = %v after a release in place", app.layout, app.draggingDivider)
+	}
+}
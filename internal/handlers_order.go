@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// orderStrategyDescriptions explain the strategies in the status bar and
+// the Order view.
+var orderStrategyDescriptions = map[OrderStrategy]string{
+	OrderAlphabetical: "alphabetical",
+	OrderDependency:   "dependency (imported Go packages first)",
+	OrderMtime:        "mtime (most recently modified first)",
+	OrderTestsLast:    "tests last",
+}
+
+// ShowOrderView opens the Order view listing the selected files in bundle
+// order.
+func (app *App) ShowOrderView(g *gocui.Gui, v *gocui.View) error {
+	if !app.hasSelection(g) {
+		return nil
+	}
+	app.mutex.Lock()
+	app.showOrder = true
+	app.orderCursor = 0
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the view
+}
+
+// CloseOrderView closes the Order view.
+func (app *App) CloseOrderView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showOrder = false
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(OrderViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// OrderCursorUp moves the Order view cursor up.
+func (app *App) OrderCursorUp(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.orderCursor = max(0, app.orderCursor-1)
+	app.mutex.Unlock()
+	app.renderOrderView(g)
+	return nil
+}
+
+// OrderCursorDown moves the Order view cursor down.
+func (app *App) OrderCursorDown(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.orderCursor = max(0, min(len(app.selectedFiles)-1, app.orderCursor+1))
+	app.mutex.Unlock()
+	app.renderOrderView(g)
+	return nil
+}
+
+// MoveFileUp moves the file under the Order view cursor one place earlier in
+// the bundle.
+func (app *App) MoveFileUp(g *gocui.Gui, v *gocui.View) error {
+	return app.moveFile(g, -1)
+}
+
+// MoveFileDown moves the file under the Order view cursor one place later in
+// the bundle.
+func (app *App) MoveFileDown(g *gocui.Gui, v *gocui.View) error {
+	return app.moveFile(g, 1)
+}
+
+// moveFile swaps the file under the cursor with its neighbour delta places
+// away. The moved file is pinned at its new position; an unpinned neighbour
+// is left to the order strategy.
+func (app *App) moveFile(g *gocui.Gui, delta int) error {
+	app.mutex.Lock()
+	selection := app.selectionOrder()
+	from, to := app.orderCursor, app.orderCursor+delta
+	app.mutex.Unlock()
+
+	order := app.inBundleOrder(selection)
+	if from < 0 || from >= len(order) || to < 0 || to >= len(order) {
+		return nil
+	}
+
+	app.mutex.Lock()
+	app.pinnedFiles = movePinned(order, selection.pinned, from, to)
+	app.orderCursor = to
+	app.saveOrder()
+	app.mutex.Unlock()
+
+	app.renderOrderView(g)
+	return nil
+}
+
+// movePinned returns the pinned positions after moving order[from] to
+// position to. Files pinned before stay pinned where they are shown, so
+// positions cut short by a smaller selection do not jump back; a pinned
+// neighbour swaps places with the moved file.
+func movePinned(order []string, pinned map[string]int, from, to int) map[string]int {
+	moved := make(map[string]int, len(pinned)+1)
+	for position, relPath := range order {
+		if _, ok := pinned[relPath]; ok {
+			moved[relPath] = position
+		}
+	}
+	if _, ok := moved[order[to]]; ok {
+		moved[order[to]] = from
+	}
+	moved[order[from]] = to
+	return moved
+}
+
+// ResetOrder unpins the files moved by hand, leaving the order to the
+// strategy.
+func (app *App) ResetOrder(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.pinnedFiles = nil
+	app.saveOrder()
+	app.mutex.Unlock()
+
+	app.renderOrderView(g)
+	return nil
+}
+
+// CycleOrderStrategy switches to the next order strategy. Moved files are
+// unpinned, so the new strategy orders every file.
+func (app *App) CycleOrderStrategy(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	next := orderStrategies[0]
+	for i, strategy := range orderStrategies {
+		if strategy == app.orderStrategy {
+			next = orderStrategies[(i+1)%len(orderStrategies)]
+		}
+	}
+	app.orderStrategy = next
+	hadOrder := len(app.pinnedFiles) > 0
+	app.pinnedFiles = nil
+	app.saveOrder()
+	showOrder := app.showOrder
+	app.mutex.Unlock()
+
+	if showOrder {
+		app.renderOrderView(g)
+		return nil
+	}
+
+	statusMsg := fmt.Sprintf("Bundle order: %s.", orderStrategyDescriptions[next])
+	if hadOrder {
+		statusMsg = fmt.Sprintf("Bundle order: %s (manual order cleared).", orderStrategyDescriptions[next])
+	}
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(3 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// saveOrder persists the pinned files and strategy. Assumes mutex is held.
+func (app *App) saveOrder() {
+	if app.cacheFilePath == "" {
+		return
+	}
+	pinned := app.pinnedFiles
+	strategy := app.orderStrategy
+	err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+		entry.Pinned = pinned
+		entry.OrderStrategy = strategy
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on saveOrder: %v\n", err)
+	}
+}
+
+// renderOrderView redraws the selected files in bundle order.
+func (app *App) renderOrderView(g *gocui.Gui) {
+	v, err := g.View(OrderViewName)
+	if err != nil {
+		return
+	}
+
+	app.mutex.Lock()
+	selection := app.selectionOrder()
+	app.mutex.Unlock()
+
+	order := app.inBundleOrder(selection)
+
+	app.mutex.Lock()
+	cursor := min(app.orderCursor, max(0, len(order)-1))
+	app.orderCursor = cursor
+	app.mutex.Unlock()
+
+	v.Title = fmt.Sprintf(" Bundle order: %s ", orderStrategyDescriptions[selection.strategy])
+	v.Clear()
+	width := len(fmt.Sprint(len(order)))
+	for i, relPath := range order {
+		marker := " "
+		if _, ok := selection.pinned[relPath]; ok {
+			marker = "*" // Moved by hand
+		}
+		fmt.Fprintf(v, "%*d.%s %s\n", width, i+1, marker, relPath)
+	}
+	_ = v.SetCursor(0, cursor)
+	if _, height := v.Size(); cursor >= height {
+		_ = v.SetOrigin(0, cursor-height+1)
+		_ = v.SetCursor(0, height-1)
+	} else {
+		_ = v.SetOrigin(0, 0)
+	}
+}
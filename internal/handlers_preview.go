@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ShowCopyPreview opens a full-screen preview of exactly what CopyAllSelected
+// would put on the clipboard: every part, in order, if the bundle is split.
+func (app *App) ShowCopyPreview(g *gocui.Gui, v *gocui.View) error {
+	if !app.hasSelection(g) {
+		return nil
+	}
+
+	app.mutex.Lock()
+	selection := app.selectionOrder()
+	tokenizer := app.tokenizer
+	app.mutex.Unlock()
+
+	selectedPaths := app.inBundleOrder(selection)
+
+	result := app.buildBundle(selectedPaths)
+	text := app.withInstruction(result.content)
+	parts := app.splitBundle(result)
+	if parts != nil {
+		texts := make([]string, len(parts))
+		for i, part := range parts {
+			texts[i] = part.text
+		}
+		text = strings.Join(texts, "\n")
+	}
+
+	tokens := "n/a"
+	if tokenizer != nil {
+		tokens = fmt.Sprintf("%d", len(tokenizer.Encode(text, nil, nil)))
+	}
+	title := fmt.Sprintf(" Copy preview: %d file(s), %s tokens, %d chars", result.files, tokens, len(text))
+	if parts != nil {
+		title += fmt.Sprintf(", %d parts", len(parts))
+	}
+	if len(result.redacted) > 0 {
+		title += ", secrets redacted"
+	}
+	title += fmt.Sprintf(" (%s: Copy, %s: Cancel) ",
+		app.keyHints(CopyPreviewViewName, "copy", "confirm"), app.keyHint(CopyPreviewViewName, "close"))
+
+	app.mutex.Lock()
+	app.previewText = text
+	app.previewTitle = title
+	app.showCopyPreview = true
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the preview
+}
+
+// ConfirmCopyPreview closes the preview and copies the selected files.
+func (app *App) ConfirmCopyPreview(g *gocui.Gui, v *gocui.View) error {
+	if err := app.CloseCopyPreview(g, v); err != nil {
+		return err
+	}
+	return app.CopyAllSelected(g, v)
+}
+
+// CloseCopyPreview closes the preview without copying.
+func (app *App) CloseCopyPreview(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showCopyPreview = false
+	app.previewText = ""
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(CopyPreviewViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// ScrollPreviewLineUp scrolls the preview up one line.
+func (app *App) ScrollPreviewLineUp(g *gocui.Gui, v *gocui.View) error {
+	return scrollPreview(v, -1)
+}
+
+// ScrollPreviewLineDown scrolls the preview down one line.
+func (app *App) ScrollPreviewLineDown(g *gocui.Gui, v *gocui.View) error {
+	return scrollPreview(v, 1)
+}
+
+// ScrollPreviewPageUp scrolls the preview up one page.
+func (app *App) ScrollPreviewPageUp(g *gocui.Gui, v *gocui.View) error {
+	_, vy := v.Size()
+	return scrollPreview(v, -max(1, vy-1))
+}
+
+// ScrollPreviewPageDown scrolls the preview down one page.
+func (app *App) ScrollPreviewPageDown(g *gocui.Gui, v *gocui.View) error {
+	_, vy := v.Size()
+	return scrollPreview(v, max(1, vy-1))
+}
+
+// ScrollPreviewTop jumps to the start of the preview.
+func (app *App) ScrollPreviewTop(g *gocui.Gui, v *gocui.View) error {
+	return v.SetOrigin(0, 0)
+}
+
+// ScrollPreviewBottom jumps to the end of the preview.
+func (app *App) ScrollPreviewBottom(g *gocui.Gui, v *gocui.View) error {
+	return scrollPreview(v, v.ViewLinesHeight())
+}
+
+// scrollPreview moves the preview by amount lines, stopping with the last
+// line at the bottom of the view.
+func scrollPreview(v *gocui.View, amount int) error {
+	ox, oy := v.Origin()
+	_, vy := v.Size()
+	newOy := min(oy+amount, v.ViewLinesHeight()-vy)
+	return v.SetOrigin(ox, max(0, newOy))
+}
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ToggleVisualMode starts marking a line range in the content view, or, when
+// already marking, stores the marked range as a partial selection of the file.
+func (app *App) ToggleVisualMode(g *gocui.Gui, v *gocui.View) error {
+	if v == nil || v.Name() != ContentViewName {
+		return nil
+	}
+
+	app.mutex.Lock()
+	relPath := app.currentlyPreviewedFile
+	if relPath == "" {
+		app.mutex.Unlock()
+		return nil
+	}
+
+	if !app.visualActive {
+		app.visualActive = true
+		app.previewTransformed = false // Marking works on the raw file's lines
+		app.visualAnchor = app.contentViewOriginY
+		app.visualCursor = app.contentViewOriginY
+		app.mutex.Unlock()
+		app.refreshContentView(g)
+		return nil
+	}
+
+	r := LineRange{
+		Start: min(app.visualAnchor, app.visualCursor) + 1,
+		End:   max(app.visualAnchor, app.visualCursor) + 1,
+	}
+	confirmFile := app.pendingSensitive
+	app.pendingSensitive = ""
+	if isSensitivePath(relPath) && !app.selectedFiles[relPath] && confirmFile != relPath {
+		// Like Space in the Files view, marking selects the file, so files
+		// like .env and *.pem need a second press to be included.
+		app.pendingSensitive = relPath
+		app.mutex.Unlock()
+		app.updateStatus(g, fmt.Sprintf("%s may contain secrets. Press %s again to mark lines %d-%d.",
+			relPath, app.keyHint(ContentViewName, "mark_lines"), r.Start, r.End))
+		return nil
+	}
+	app.visualActive = false
+	app.addLineRange(relPath, r)
+	app.selectedFiles[relPath] = true
+	app.mutex.Unlock()
+
+	app.refreshContentView(g)
+	app.refreshFilesView(g)
+
+	statusMsg := fmt.Sprintf("Marked lines %d-%d of %s.", r.Start, r.End, relPath)
+	app.updateStatus(g, statusMsg)
+	go func(msg string) {
+		time.Sleep(2 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+	return nil
+}
+
+// CancelVisualMode abandons the range being marked. Without an active range it
+// returns focus to the Files view.
+func (app *App) CancelVisualMode(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	wasActive := app.visualActive
+	app.visualActive = false
+	app.mutex.Unlock()
+
+	if wasActive {
+		app.refreshContentView(g)
+		return nil
+	}
+
+	if _, err := g.SetCurrentView(FilesViewName); err != nil {
+		return err
+	}
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// ClearLineRanges removes the partial selection of the previewed file, so the
+// whole file is bundled again (it stays selected).
+func (app *App) ClearLineRanges(g *gocui.Gui, v *gocui.View) error {
+	if v == nil || v.Name() != ContentViewName {
+		return nil
+	}
+
+	app.mutex.Lock()
+	relPath := app.currentlyPreviewedFile
+	_, hadRanges := app.lineRanges[relPath]
+	delete(app.lineRanges, relPath)
+	app.visualActive = false
+	app.mutex.Unlock()
+
+	if !hadRanges {
+		return nil
+	}
+	app.refreshContentView(g)
+	app.refreshFilesView(g)
+	return nil
+}
+
+// moveVisualCursor moves the range marking cursor by amount lines, scrolling
+// the content view to keep it visible.
+func (app *App) moveVisualCursor(g *gocui.Gui, amount int) error {
+	v, err := g.View(ContentViewName)
+	if err != nil {
+		return nil
+	}
+	_, viewHeight := v.Size()
+	lineCount := len(v.BufferLines())
+
+	app.mutex.Lock()
+	app.visualCursor = max(0, min(lineCount-1, app.visualCursor+amount))
+	cursor := app.visualCursor
+	if cursor < app.contentViewOriginY {
+		app.contentViewOriginY = cursor
+	} else if cursor >= app.contentViewOriginY+viewHeight {
+		app.contentViewOriginY = cursor - viewHeight + 1
+	}
+	app.mutex.Unlock()
+
+	app.refreshContentView(g)
+	return nil
+}
+
+// isVisualActive reports whether a line range is being marked.
+func (app *App) isVisualActive() bool {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	return app.visualActive
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// PromptReferences asks for the identifier whose references should be
+// selected. From the Content view the prompt is prefilled with the identifier
+// of the current line.
+func (app *App) PromptReferences(g *gocui.Gui, v *gocui.View) error {
+	initial := ""
+	if v != nil && v.Name() == ContentViewName {
+		initial = app.currentContentIdentifier()
+	}
+
+	app.mutex.Lock()
+	app.showRefsPrompt = true
+	app.refsPromptText = initial
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the prompt
+}
+
+// currentContentIdentifier picks the identifier of the line at the top of the
+// Content view, or under the marking cursor while marking.
+func (app *App) currentContentIdentifier() string {
+	app.mutex.Lock()
+	relPath := app.currentlyPreviewedFile
+	lineIndex := app.contentViewOriginY
+	if app.visualActive {
+		lineIndex = app.visualCursor
+	}
+	app.mutex.Unlock()
+
+	if relPath == "" {
+		return ""
+	}
+	content, err := app.readFileContent(relPath)
+	if err != nil {
+		return ""
+	}
+	lines := splitLines(string(content))
+	if lineIndex < 0 || lineIndex >= len(lines) {
+		return ""
+	}
+	return pickIdentifier(lines[lineIndex])
+}
+
+// SearchReferences runs the search for the identifier typed in the prompt and
+// lists the hits for confirmation.
+func (app *App) SearchReferences(g *gocui.Gui, v *gocui.View) error {
+	input := strings.TrimSpace(v.Buffer())
+	qualifier, ident := normalizeIdentifier(input)
+	if ident == "" {
+		app.updateStatus(g, fmt.Sprintf("Not an identifier: %q", input))
+		return nil
+	}
+
+	hits := app.findReferences(qualifier, ident)
+	if qualifier != "" {
+		ident = qualifier + "." + ident
+	}
+
+	app.mutex.Lock()
+	app.showRefsPrompt = false
+	app.showReferences = true
+	app.refsIdent = ident
+	app.refsHits = hits
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(RefsPromptViewName)
+	return nil // Layout shows the results
+}
+
+// ConfirmReferences selects the visible files listed in the References view.
+func (app *App) ConfirmReferences(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	ident := app.refsIdent
+	added, hidden, sensitive := 0, 0, 0
+	for _, hit := range app.refsHits {
+		switch {
+		case hit.hidden:
+			hidden++ // Selection only holds files shown in the Files view
+		case isSensitivePath(hit.path):
+			sensitive++ // Needs explicit confirmation (see ToggleSelect)
+		case !app.selectedFiles[hit.path]:
+			app.selectedFiles[hit.path] = true
+			added++
+		}
+	}
+	app.mutex.Unlock()
+
+	statusMsg := fmt.Sprintf("Selected %d more file(s) referencing %s.", added, ident)
+	var skipped []string
+	if hidden > 0 {
+		skipped = append(skipped, fmt.Sprintf("%d hidden by filter", hidden))
+	}
+	if sensitive > 0 {
+		skipped = append(skipped, fmt.Sprintf("%d sensitive", sensitive))
+	}
+	if len(skipped) > 0 {
+		statusMsg = fmt.Sprintf("Selected %d more file(s) referencing %s (skipped %s).", added, ident, strings.Join(skipped, ", "))
+	}
+
+	if err := app.CloseReferences(g, v); err != nil {
+		return err
+	}
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(3 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// CloseReferences closes the prompt or the References view without selecting.
+func (app *App) CloseReferences(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showRefsPrompt = false
+	app.showReferences = false
+	app.refsHits = nil
+	app.statusMessage = "" // Drop prompt errors
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(RefsPromptViewName)
+	_ = g.DeleteView(ReferencesViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// ScrollReferencesUp scrolls the References view up one line.
+func (app *App) ScrollReferencesUp(g *gocui.Gui, v *gocui.View) error {
+	ox, oy := v.Origin()
+	if oy > 0 {
+		return v.SetOrigin(ox, oy-1)
+	}
+	return nil
+}
+
+// ScrollReferencesDown scrolls the References view down one line.
+func (app *App) ScrollReferencesDown(g *gocui.Gui, v *gocui.View) error {
+	ox, oy := v.Origin()
+	_, vy := v.Size()
+	if oy+vy < len(v.BufferLines())-1 {
+		return v.SetOrigin(ox, oy+1)
+	}
+	return nil
+}
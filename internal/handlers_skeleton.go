@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ToggleSkeleton switches the Go file under the cursor between full and
+// skeleton rendering in the bundle.
+func (app *App) ToggleSkeleton(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	if app.currentLine < 0 || app.currentLine >= len(app.fileList) {
+		app.mutex.Unlock()
+		return nil
+	}
+	relPath := app.fileList[app.currentLine]
+
+	var statusMsg string
+	switch {
+	case !supportsSkeleton(relPath):
+		statusMsg = "Skeleton mode is only available for Go files."
+	case app.renderModes[relPath] == RenderSkeleton:
+		delete(app.renderModes, relPath)
+		statusMsg = fmt.Sprintf("%s: full content.", relPath)
+	default:
+		app.renderModes[relPath] = RenderSkeleton
+		statusMsg = fmt.Sprintf("%s: skeleton (signatures only).", relPath)
+	}
+	app.mutex.Unlock()
+
+	app.refreshFilesView(g)
+	app.refreshContentView(g)
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(2 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
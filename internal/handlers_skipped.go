@@ -0,0 +1,306 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ShowSkippedView opens the modal listing every path rejected during discovery
+// or filtering, grouped by reason.
+func (app *App) ShowSkippedView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	if app.isLoading {
+		app.mutex.Unlock()
+		return nil // Nothing recorded yet
+	}
+	app.buildSkippedViewLines()
+	app.skippedViewCursor = app.nextSkippedEntry(-1, 1)
+	app.showSkippedView = true
+	app.mutex.Unlock()
+
+	// Trigger layout update which will create/show the skipped view
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// CloseSkippedView hides the skipped view and returns to the normal file browser UI.
+func (app *App) CloseSkippedView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showSkippedView = false
+	app.statusMessage = "" // Don't carry skipped view messages back
+	app.skippedViewLines = nil
+	app.skippedViewCursor = 0
+	app.mutex.Unlock()
+
+	if err := g.DeleteView(SkippedViewName); err != nil && err != gocui.ErrUnknownView {
+		// Log or handle error if needed
+	}
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	g.Update(func(g *gocui.Gui) error {
+		_, err := g.SetCurrentView(FilesViewName)
+		return err
+	})
+	return nil
+}
+
+// buildSkippedViewLines renders app.skippedFiles and app.filterSkipped into
+// app.skippedViewLines. Assumes mutex is held.
+func (app *App) buildSkippedViewLines() {
+	groups := make(map[SkipReason][]string)
+	details := make(map[string]SkippedFile)
+	for path, entry := range app.skippedFiles {
+		groups[entry.Reason] = append(groups[entry.Reason], path)
+		details[path] = entry
+	}
+	for path, entry := range app.filterSkipped {
+		groups[entry.Reason] = append(groups[entry.Reason], path)
+		details[path] = entry
+	}
+
+	lines := []skippedViewLine{}
+	for _, reason := range skipReasonOrder {
+		paths := groups[reason]
+		if len(paths) == 0 {
+			continue
+		}
+		sort.Strings(paths)
+		if len(lines) > 0 {
+			lines = append(lines, skippedViewLine{})
+		}
+		lines = append(lines, skippedViewLine{
+			text: paint(app.theme.Heading, fmt.Sprintf("%s (%d)", skipReasonLabels[reason], len(paths))),
+		})
+		for _, path := range paths {
+			marker := "[ ]"
+			switch {
+			case app.forceIncluded[path]:
+				marker = "[F]"
+			case app.expandedDirs[path]:
+				marker = "[-]"
+			case strings.HasSuffix(path, "/") && !strings.HasSuffix(path, "/."):
+				marker = "[+]" // Lists its files when toggled
+			}
+			text := fmt.Sprintf("  %s %s", marker, path)
+			if detail := details[path].Detail; detail != "" {
+				text += fmt.Sprintf("  (%s)", detail)
+			}
+			lines = append(lines, skippedViewLine{text: text, path: path})
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, skippedViewLine{text: "No paths were skipped."})
+	}
+	app.skippedViewLines = lines
+}
+
+// nextSkippedEntry returns the index of the next selectable line after from,
+// moving in direction (+1/-1). Returns from when there is none. Assumes mutex is held.
+func (app *App) nextSkippedEntry(from, direction int) int {
+	for i := from + direction; i >= 0 && i < len(app.skippedViewLines); i += direction {
+		if app.skippedViewLines[i].path != "" {
+			return i
+		}
+	}
+	if from < 0 {
+		return 0
+	}
+	return from
+}
+
+// moveSkippedCursor moves the cursor over selectable entries, steps times.
+func (app *App) moveSkippedCursor(g *gocui.Gui, v *gocui.View, direction, steps int) error {
+	if v == nil || v.Name() != SkippedViewName {
+		return nil
+	}
+	app.mutex.Lock()
+	for i := 0; i < steps; i++ {
+		app.skippedViewCursor = app.nextSkippedEntry(app.skippedViewCursor, direction)
+	}
+	app.mutex.Unlock()
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	return nil
+}
+
+// SkippedCursorUp moves the skipped view cursor to the previous entry.
+func (app *App) SkippedCursorUp(g *gocui.Gui, v *gocui.View) error {
+	return app.moveSkippedCursor(g, v, -1, 1)
+}
+
+// SkippedCursorDown moves the skipped view cursor to the next entry.
+func (app *App) SkippedCursorDown(g *gocui.Gui, v *gocui.View) error {
+	return app.moveSkippedCursor(g, v, 1, 1)
+}
+
+// SkippedPageUp moves the skipped view cursor up by a page.
+func (app *App) SkippedPageUp(g *gocui.Gui, v *gocui.View) error {
+	if v == nil {
+		return nil
+	}
+	_, vy := v.Size()
+	return app.moveSkippedCursor(g, v, -1, max(1, vy-1))
+}
+
+// SkippedPageDown moves the skipped view cursor down by a page.
+func (app *App) SkippedPageDown(g *gocui.Gui, v *gocui.View) error {
+	if v == nil {
+		return nil
+	}
+	_, vy := v.Size()
+	return app.moveSkippedCursor(g, v, 1, max(1, vy-1))
+}
+
+// ToggleForceInclude force-includes (or stops force-including) the path under
+// the skipped view cursor. The choice is persisted per directory in the cache.
+// On a skipped directory it lists or hides the directory's files instead (see
+// toggleExpandedDir).
+func (app *App) ToggleForceInclude(g *gocui.Gui, v *gocui.View) error {
+	if v == nil || v.Name() != SkippedViewName {
+		return nil
+	}
+
+	app.mutex.Lock()
+	if app.skippedViewCursor < 0 || app.skippedViewCursor >= len(app.skippedViewLines) {
+		app.mutex.Unlock()
+		return nil
+	}
+	path := app.skippedViewLines[app.skippedViewCursor].path
+	if path == "" {
+		app.mutex.Unlock()
+		return nil
+	}
+	if path == "." || strings.HasSuffix(path, "/.") {
+		app.mutex.Unlock()
+		app.updateStatus(g, fmt.Sprintf("Cannot force-include root %s; only files can be included.", path))
+		return nil
+	}
+	if strings.HasSuffix(path, "/") {
+		app.mutex.Unlock()
+		return app.toggleExpandedDir(g, path)
+	}
+
+	var statusMsg string
+	_, skippedDuringWalk := app.skippedFiles[path]
+	if app.forceIncluded[path] {
+		delete(app.forceIncluded, path)
+		if skippedDuringWalk {
+			app.allFiles = removeString(app.allFiles, path)
+		}
+		statusMsg = fmt.Sprintf("No longer force-including %s.", path)
+	} else {
+		app.forceIncluded[path] = true
+		if skippedDuringWalk {
+			app.allFiles = insertSorted(app.allFiles, path)
+		}
+		statusMsg = fmt.Sprintf("Force-included %s.", path)
+	}
+
+	// --- Update Cache ---
+	if app.cacheFilePath != "" {
+		err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+			entry.ForceIncludes = app.forceIncludeList()
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on ToggleForceInclude: %v\n", err)
+		}
+	}
+
+	app.applyFilters() // Unlocks the mutex
+
+	app.mutex.Lock()
+	app.buildSkippedViewLines()
+	if app.skippedViewCursor >= len(app.skippedViewLines) {
+		app.skippedViewCursor = app.nextSkippedEntry(len(app.skippedViewLines), -1)
+	}
+	app.mutex.Unlock()
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(2 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			app.mutex.Lock()
+			showSkipped := app.showSkippedView
+			app.mutex.Unlock()
+			if err == nil && showSkipped && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatusForSkippedView(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// toggleExpandedDir lists the files of the skipped directory dir in the
+// Skipped view, so they can be force-included one by one, or hides them
+// again. Assumes mutex is not held.
+func (app *App) toggleExpandedDir(g *gocui.Gui, dir string) error {
+	app.mutex.Lock()
+	expanded := app.expandedDirs[dir]
+	entry := app.skippedFiles[dir]
+	app.mutex.Unlock()
+
+	var files []string
+	if !expanded {
+		files = app.skippedDirFiles(dir) // Reads the directory without the lock
+	}
+
+	app.mutex.Lock()
+	var statusMsg string
+	if expanded {
+		delete(app.expandedDirs, dir)
+		for path, skipped := range app.skippedFiles {
+			if skipped.Detail == "in "+dir && !app.forceIncluded[path] {
+				delete(app.skippedFiles, path)
+			}
+		}
+		statusMsg = fmt.Sprintf("Collapsed %s.", dir)
+	} else {
+		app.expandedDirs[dir] = true
+		for _, path := range files {
+			if _, ok := app.skippedFiles[path]; !ok {
+				app.skippedFiles[path] = SkippedFile{Reason: entry.Reason, Detail: "in " + dir}
+			}
+		}
+		statusMsg = fmt.Sprintf("Listed %d file(s) in %s.", len(files), dir)
+		if len(files) == maxExpandedFiles {
+			statusMsg = fmt.Sprintf("Listed the first %d files in %s.", len(files), dir)
+		}
+	}
+	app.buildSkippedViewLines()
+	app.mutex.Unlock()
+
+	g.Update(func(g *gocui.Gui) error {
+		return app.Layout(g)
+	})
+	app.updateStatus(g, statusMsg)
+	return nil
+}
+
+// forceIncludeList returns the force-included paths, sorted. Assumes mutex is held.
+func (app *App) forceIncludeList() []string {
+	paths := make([]string, 0, len(app.forceIncluded))
+	for path := range app.forceIncluded {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
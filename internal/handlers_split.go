@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// CopyNextPart copies the part after the one last copied from a split bundle.
+func (app *App) CopyNextPart(g *gocui.Gui, v *gocui.View) error {
+	return app.stepBundlePart(g, 1)
+}
+
+// CopyPreviousPart copies the part before the one last copied from a split
+// bundle.
+func (app *App) CopyPreviousPart(g *gocui.Gui, v *gocui.View) error {
+	return app.stepBundlePart(g, -1)
+}
+
+// stepBundlePart moves delta parts from the one last copied and copies it.
+func (app *App) stepBundlePart(g *gocui.Gui, delta int) error {
+	app.mutex.Lock()
+	app.dropStaleBundleParts()
+	count := len(app.bundleParts)
+	index := app.bundlePart + delta
+	app.mutex.Unlock()
+
+	var statusMsg string
+	switch {
+	case count == 0:
+		statusMsg = fmt.Sprintf("No split bundle. Copy with %s first (see --split-tokens/--split-bytes).", app.keyHint(FilesViewName, "copy"))
+	case index >= count:
+		statusMsg = fmt.Sprintf("Part %d/%d was the last part. Press %s to split and copy again.", count, count, app.keyHint(FilesViewName, "copy"))
+	case index < 0:
+		statusMsg = fmt.Sprintf("Part 1/%d is the first part.", count)
+	default:
+		return app.copyBundlePart(g, index)
+	}
+
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(3 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// bundleStateKey describes everything the bundle is built from: the
+// selection and its order, the marked ranges and the options. Assumes mutex
+// is held.
+func (app *App) bundleStateKey() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%+v %v %s %q %v %s %q", app.transforms, app.lineNumbers, app.truncation,
+		app.instruction, app.instructionAfter, app.orderStrategy, app.pinnedFiles)
+	for _, relPath := range app.fileList {
+		if !app.selectedFiles[relPath] {
+			continue
+		}
+		t, overridden := app.truncations[relPath]
+		fmt.Fprintf(&b, "\x00%s %v %d %v %s", relPath, app.lineRanges[relPath], app.renderModes[relPath], overridden, t)
+	}
+	return b.String()
+}
+
+// dropStaleBundleParts forgets the parts of the last split copy once the
+// selection, its ranges or the bundle options changed, so ] doesn't copy
+// parts of an outdated bundle. Assumes mutex is held.
+func (app *App) dropStaleBundleParts() {
+	if app.bundleParts != nil && app.bundleStateKey() != app.partsKey {
+		app.bundleParts, app.bundlePart = nil, 0
+	}
+}
+
+// copyBundlePart puts part index of the split bundle on the clipboard.
+func (app *App) copyBundlePart(g *gocui.Gui, index int) error {
+	app.mutex.Lock()
+	app.bundlePart = index
+	part := app.bundleParts[index]
+	count := len(app.bundleParts)
+	app.mutex.Unlock()
+
+	what := fmt.Sprintf("part %d/%d (%d file(s), last part)", index+1, count, len(part.paths))
+	if index < count-1 {
+		what = fmt.Sprintf("part %d/%d (%d file(s); %s copies part %d/%d)", index+1, count, len(part.paths), app.keyHint(FilesViewName, "copy_next_part"), index+2, count)
+	}
+	result := bundle{files: len(part.paths), redacted: part.redacted}
+	return app.copyText(g, part.text, result, what)
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// ShowTemplatesView opens the prompt template picker for the selected files.
+// The first entry copies the plain bundle.
+func (app *App) ShowTemplatesView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	if len(app.selectedFiles) == 0 {
+		app.mutex.Unlock()
+		app.updateStatus(g, "No files selected to copy.")
+		go func() {
+			time.Sleep(2 * time.Second)
+			g.Update(func(g *gocui.Gui) error {
+				sv, err := g.View(StatusViewName)
+				if err == nil && strings.HasPrefix(sv.Buffer(), "No files") {
+					app.resetStatus(g)
+				}
+				return nil
+			})
+		}()
+		return nil
+	}
+	app.mutex.Unlock()
+
+	templates := app.findPromptTemplates()
+
+	app.mutex.Lock()
+	app.promptTemplates = templates
+	app.templatesCursor = 0
+	app.showTemplates = true
+	app.mutex.Unlock()
+	return nil // Layout creates and focuses the picker
+}
+
+// CloseTemplatesView closes the template picker without copying.
+func (app *App) CloseTemplatesView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showTemplates = false
+	app.promptTemplates = nil
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(TemplatesViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// TemplatesCursorUp moves the picker cursor up.
+func (app *App) TemplatesCursorUp(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.templatesCursor = max(0, app.templatesCursor-1)
+	app.mutex.Unlock()
+	app.renderTemplatesView(g)
+	return nil
+}
+
+// TemplatesCursorDown moves the picker cursor down.
+func (app *App) TemplatesCursorDown(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.templatesCursor = min(len(app.promptTemplates), app.templatesCursor+1)
+	app.mutex.Unlock()
+	app.renderTemplatesView(g)
+	return nil
+}
+
+// CopyWithTemplate copies the selected files through the template under the
+// picker cursor, or the plain bundle for the first entry.
+func (app *App) CopyWithTemplate(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	cursor := app.templatesCursor
+	var chosen *promptTemplate
+	if cursor > 0 && cursor <= len(app.promptTemplates) {
+		t := app.promptTemplates[cursor-1]
+		chosen = &t
+	}
+	selection := app.selectionOrder()
+	app.mutex.Unlock()
+
+	if err := app.CloseTemplatesView(g, v); err != nil {
+		return err
+	}
+
+	result := app.buildBundle(app.inBundleOrder(selection))
+	if chosen == nil {
+		return app.copyText(g, app.withInstruction(result.content), result, fmt.Sprintf("content of %d file(s)", result.files))
+	}
+
+	text, err := app.renderPromptTemplate(*chosen, result)
+	if err != nil {
+		statusMsg := fmt.Sprintf("Template %s failed: %v", chosen.name, err)
+		app.updateStatus(g, statusMsg)
+		go func(msg string) {
+			time.Sleep(5 * time.Second)
+			g.Update(func(g *gocui.Gui) error {
+				sv, err := g.View(StatusViewName)
+				if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+					app.resetStatus(g)
+				}
+				return nil
+			})
+		}(statusMsg)
+		return nil
+	}
+	return app.copyText(g, text, result, fmt.Sprintf("prompt %q with %d file(s)", chosen.name, result.files))
+}
+
+// renderTemplatesView redraws the picker entries and cursor.
+func (app *App) renderTemplatesView(g *gocui.Gui) {
+	v, err := g.View(TemplatesViewName)
+	if err != nil {
+		return
+	}
+
+	app.mutex.Lock()
+	templates := app.promptTemplates
+	cursor := app.templatesCursor
+	app.mutex.Unlock()
+
+	v.Clear()
+	fmt.Fprintln(v, "Plain bundle (no template)")
+	for _, t := range templates {
+		fmt.Fprintf(v, "%-30s (%s)\n", t.name, t.origin)
+	}
+	if len(templates) == 0 {
+		dirs, _ := app.promptTemplateDirs()
+		fmt.Fprintf(v, "\nNo templates found. Add *%s files to:\n", PromptTemplateExt)
+		for _, dir := range dirs {
+			fmt.Fprintf(v, "  %s\n", dir)
+		}
+	}
+	_ = v.SetCursor(0, cursor)
+}
@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// transformOptions lists the transforms shown in the Transforms view, in the
+// order of their number keys.
+var transformOptions = []struct {
+	label string
+	field func(*Transforms) *bool
+}{
+	{"Strip comments (Go, JS/TS, Rust, C-like, Python, shell)", func(t *Transforms) *bool { return &t.StripComments }},
+	{"Collapse runs of blank lines", func(t *Transforms) *bool { return &t.CollapseBlank }},
+	{"Strip trailing whitespace", func(t *Transforms) *bool { return &t.TrimTrailing }},
+	{"Strip leading license/copyright header", func(t *Transforms) *bool { return &t.StripLicense }},
+}
+
+// ToggleTransformsView opens or closes the Transforms overlay.
+func (app *App) ToggleTransformsView(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showTransforms = !app.showTransforms
+	show := app.showTransforms
+	app.mutex.Unlock()
+
+	if !show {
+		_ = g.DeleteView(TransformsViewName)
+		_, err := g.SetCurrentView(FilesViewName)
+		return err
+	}
+	return nil // Layout creates and focuses the overlay
+}
+
+// toggleTransform returns a handler flipping the transform at index, which is
+// remembered for this directory.
+func (app *App) toggleTransform(index int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		app.mutex.Lock()
+		enabled := transformOptions[index].field(&app.transforms)
+		*enabled = !*enabled
+		transforms := app.transforms
+
+		// --- Update Cache ---
+		if app.cacheFilePath != "" {
+			err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+				entry.Transforms = transforms
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on toggleTransform: %v\n", err)
+			}
+		}
+		app.mutex.Unlock()
+
+		app.renderTransformsView(g)
+		app.refreshContentView(g)
+		app.renderStatus(g)
+		return nil
+	}
+}
+
+// TogglePreviewTransformed switches the Content view between the raw file and
+// the file as it will be bundled with the active transforms and render mode.
+func (app *App) TogglePreviewTransformed(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	if app.visualActive {
+		app.mutex.Unlock()
+		return nil // Marking needs the raw line layout
+	}
+	app.previewTransformed = !app.previewTransformed
+	preview := app.previewTransformed
+	active := app.transforms.Any() || app.renderModes[app.currentlyPreviewedFile] == RenderSkeleton
+	app.mutex.Unlock()
+
+	app.refreshContentView(g)
+
+	statusMsg := "Previewing raw file."
+	if preview && active {
+		statusMsg = "Previewing file as bundled."
+	} else if preview {
+		statusMsg = "No transforms enabled (t in Files view)."
+	}
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(2 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// renderTransformsView redraws the transform checklist.
+func (app *App) renderTransformsView(g *gocui.Gui) {
+	v, err := g.View(TransformsViewName)
+	if err != nil {
+		return
+	}
+
+	app.mutex.Lock()
+	transforms := app.transforms
+	app.mutex.Unlock()
+
+	v.Clear()
+	for i, option := range transformOptions {
+		mark := "[ ]"
+		if *option.field(&transforms) {
+			mark = "[x]"
+		}
+		fmt.Fprintf(v, " %d  %s %s\n", i+1, mark, option.label)
+	}
+	fmt.Fprintln(v, "\n Applied to the copied bundle and its token count.")
+	fmt.Fprintln(v, " p in the Content view previews the transformed file.")
+}
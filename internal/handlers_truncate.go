@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// PromptTruncation asks for the truncation rule of the file under the cursor,
+// prefilled with the rule it currently gets.
+func (app *App) PromptTruncation(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.currentLine < 0 || app.currentLine >= len(app.fileList) {
+		return nil
+	}
+	app.truncPromptPath = app.fileList[app.currentLine]
+	app.showTruncPrompt = true
+	return nil // Layout creates and focuses the prompt
+}
+
+// ConfirmTruncation applies the rule typed in the prompt. An empty rule
+// removes the override, so the global rule applies again; "none" bundles the
+// file whole regardless of it.
+func (app *App) ConfirmTruncation(g *gocui.Gui, v *gocui.View) error {
+	spec := strings.TrimSpace(v.Buffer())
+	truncation, err := ParseTruncation(spec)
+
+	app.mutex.Lock()
+	relPath := app.truncPromptPath
+	var statusMsg string
+	switch {
+	case err != nil:
+		statusMsg = fmt.Sprintf("Error: %v", err)
+	case spec == "":
+		delete(app.truncations, relPath)
+		statusMsg = fmt.Sprintf("%s: truncation %s (global rule).", relPath, app.truncation.Label())
+	default:
+		app.truncations[relPath] = truncation
+		statusMsg = fmt.Sprintf("%s: truncation %s.", relPath, truncation.Label())
+	}
+	if err == nil {
+		app.saveTruncations()
+	}
+	app.mutex.Unlock()
+
+	if err := app.CloseTruncationPrompt(g, v); err != nil {
+		return err
+	}
+	app.refreshFilesView(g)
+	app.updateStatus(g, statusMsg)
+
+	// Schedule status reset
+	go func(msg string) {
+		time.Sleep(3 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			sv, err := g.View(StatusViewName)
+			if err == nil && strings.HasPrefix(sv.Buffer(), msg) {
+				app.resetStatus(g)
+			}
+			return nil
+		})
+	}(statusMsg)
+
+	return nil
+}
+
+// CloseTruncationPrompt closes the truncation prompt without changes.
+func (app *App) CloseTruncationPrompt(g *gocui.Gui, v *gocui.View) error {
+	app.mutex.Lock()
+	app.showTruncPrompt = false
+	app.mutex.Unlock()
+
+	_ = g.DeleteView(TruncPromptViewName)
+	_, err := g.SetCurrentView(FilesViewName)
+	return err
+}
+
+// saveTruncations persists the per-file truncation rules. Assumes mutex is
+// held.
+func (app *App) saveTruncations() {
+	if app.cacheFilePath == "" {
+		return
+	}
+	specs := make(map[string]string, len(app.truncations))
+	for relPath, t := range app.truncations {
+		specs[relPath] = t.String()
+	}
+	err := app.updateDirectoryCache(func(entry *DirectoryCache) {
+		entry.Truncations = specs
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save cache on saveTruncations: %v\n", err)
+	}
+}
+
+// truncationMarkers returns the Files view marker of each file whose
+// truncation is worth showing: every override, and selected files the
+// global rule actually cuts. Finding those reads the files, so the result
+// is kept until the selection, the rules or the files change. Assumes mutex
+// is not held.
+func (app *App) truncationMarkers(selected map[string]bool) map[string]string {
+	app.mutex.Lock()
+	global := app.truncation
+	markers := make(map[string]string, len(app.truncations))
+	for relPath, t := range app.truncations {
+		markers[relPath] = t.Label()
+	}
+	var candidates []string
+	for relPath, isSelected := range selected {
+		if _, ok := markers[relPath]; !ok && isSelected {
+			candidates = append(candidates, relPath)
+		}
+	}
+	app.mutex.Unlock()
+
+	if !global.Active() {
+		return markers
+	}
+	key := app.truncationCutKeyFor(candidates)
+	app.mutex.Lock()
+	cut, cached := app.truncationCut, key == app.truncationCutKey
+	app.mutex.Unlock()
+	if !cached {
+		cut = make(map[string]bool)
+		for _, relPath := range candidates {
+			sections, err := app.fileSections(relPath)
+			if err == nil && len(sections) == 1 && sections[0].truncated {
+				cut[relPath] = true
+			}
+		}
+		app.mutex.Lock()
+		app.truncationCutKey, app.truncationCut = key, cut
+		app.mutex.Unlock()
+	}
+	for relPath := range cut {
+		markers[relPath] = global.Label()
+	}
+	return markers
+}
+
+// truncationCutKeyFor describes everything deciding whether the global rule
+// cuts the candidates: how each is bundled and the file itself (see
+// sizeKey). Assumes mutex is not held.
+func (app *App) truncationCutKeyFor(candidates []string) string {
+	sort.Strings(candidates)
+	var b strings.Builder
+	for _, relPath := range candidates {
+		key, err := app.sizeKey(relPath)
+		if err != nil {
+			key = err.Error()
+		}
+		fmt.Fprintf(&b, "\x00%s %s", relPath, key)
+	}
+	return b.String()
+}
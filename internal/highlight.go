@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+const (
+	// DefaultSyntaxTheme is the chroma style used to highlight previews.
+	DefaultSyntaxTheme = "monokai"
+	// DefaultHighlightMaxBytes is the file size above which previews are not
+	// highlighted, so scrolling stays responsive.
+	DefaultHighlightMaxBytes = 256 * 1024
+)
+
+// Highlighter colours file previews with ANSI escapes by the syntax of their
+// file name, using a chroma style. Only foreground colours and font effects
+// are used, so the terminal background shows through.
+type Highlighter struct {
+	style     *chroma.Style
+	trueColor bool // 24-bit escapes instead of the 256-colour palette
+	maxBytes  int
+
+	mutex     sync.Mutex
+	lastPath  string // Single-entry cache: moving back and forth re-renders the same file
+	lastText  string
+	lastLines []string
+}
+
+// NewHighlighter returns a highlighter using the named chroma style, or nil
+// if theme is "none". Files larger than maxBytes are not highlighted.
+func NewHighlighter(theme string, maxBytes int, trueColor bool) (*Highlighter, error) {
+	theme = strings.ToLower(strings.TrimSpace(theme))
+	if theme == "" {
+		theme = DefaultSyntaxTheme
+	}
+	if theme == "none" {
+		return nil, nil
+	}
+	style, ok := styles.Registry[theme]
+	if !ok {
+		names := styles.Names()
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown syntax theme %q (expected none or one of: %s)", theme, strings.Join(names, ", "))
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultHighlightMaxBytes
+	}
+	return &Highlighter{style: style, trueColor: trueColor, maxBytes: maxBytes}, nil
+}
+
+// Lines returns text split into lines (as splitLines does) with ANSI colours,
+// or nil if text is too large or relPath's language is unknown. Every line
+// ends with its colours reset, so lines can be printed on their own.
+func (h *Highlighter) Lines(relPath, text string) []string {
+	if h == nil || len(text) > h.maxBytes {
+		return nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if relPath == h.lastPath && text == h.lastText {
+		return h.lastLines
+	}
+
+	lexer := lexers.Match(relPath)
+	if lexer == nil {
+		return nil
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, text)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	var line strings.Builder
+	for _, token := range iterator.Tokens() {
+		escape := h.escape(h.style.Get(token.Type))
+		for i, part := range strings.Split(token.Value, "\n") {
+			if i > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+			if part == "" {
+				continue
+			}
+			if escape == "" {
+				line.WriteString(part)
+			} else {
+				line.WriteString(escape + part + "\x1b[0m")
+			}
+		}
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	// Lexers may add a final newline; anything else means the text changed.
+	want := len(splitLines(text))
+	if len(lines) < want {
+		return nil
+	}
+	lines = lines[:want]
+
+	h.lastPath, h.lastText, h.lastLines = relPath, text, lines
+	return lines
+}
+
+// escape returns the ANSI sequence selecting entry's colour and font
+// effects. gocui parses one colour per sequence, and a colour resets the
+// effects, so the colour comes first.
+func (h *Highlighter) escape(entry chroma.StyleEntry) string {
+	var b strings.Builder
+	if entry.Colour.IsSet() {
+		c := entry.Colour
+		if h.trueColor {
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm", c.Red(), c.Green(), c.Blue())
+		} else {
+			fmt.Fprintf(&b, "\x1b[38;5;%dm", xterm256(c.Red(), c.Green(), c.Blue()))
+		}
+	}
+	if entry.Bold == chroma.Yes {
+		b.WriteString("\x1b[1m")
+	}
+	if entry.Italic == chroma.Yes {
+		b.WriteString("\x1b[3m")
+	}
+	if entry.Underline == chroma.Yes {
+		b.WriteString("\x1b[4m")
+	}
+	return b.String()
+}
+
+// xterm256 returns the colour of the xterm 256-colour palette (the 6x6x6
+// cube or the grey ramp) closest to r, g, b.
+func xterm256(r, g, b uint8) int {
+	cubeLevels := [6]int{0, 95, 135, 175, 215, 255}
+	nearest := func(v uint8) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(int(v)-level) < abs(int(v)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearest(r), nearest(g), nearest(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := sq(int(r)-cubeLevels[ri]) + sq(int(g)-cubeLevels[gi]) + sq(int(b)-cubeLevels[bi])
+
+	grey := (int(r) + int(g) + int(b)) / 3
+	greyIndex := min(23, max(0, (grey-8+5)/10))
+	greyLevel := 8 + 10*greyIndex
+	greyDist := sq(int(r)-greyLevel) + sq(int(g)-greyLevel) + sq(int(b)-greyLevel)
+
+	if greyDist < cubeDist {
+		return 232 + greyIndex
+	}
+	return cube
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sq(n int) int { return n * n }
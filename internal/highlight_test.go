@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestNewHighlighter(t *testing.T) {
+	if h, err := NewHighlighter(" None ", 0, false); h != nil || err != nil {
+		t.Errorf(`NewHighlighter("none") = %v, %v; want no highlighter`, h, err)
+	}
+	if _, err := NewHighlighter("sparkly", 0, false); err == nil {
+		t.Error("unknown theme accepted")
+	}
+	h, err := NewHighlighter("", 0, false)
+	if err != nil || h.maxBytes != DefaultHighlightMaxBytes {
+		t.Errorf(`NewHighlighter("") = %+v, %v; want the default theme and size`, h, err)
+	}
+}
+
+func TestHighlighterLines(t *testing.T) {
+	source := "package main\n\n// Greeting\nconst s = `a\nb`\n" // The raw string spans lines
+
+	for _, trueColor := range []bool{false, true} {
+		h, err := NewHighlighter("monokai", 0, trueColor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := h.Lines("main.go", source)
+		want := splitLines(source)
+		if len(lines) != len(want) {
+			t.Fatalf("trueColor %v: %d lines, want %d", trueColor, len(lines), len(want))
+		}
+		for i, line := range lines {
+			if plain := ansiEscape.ReplaceAllString(line, ""); plain != want[i] {
+				t.Errorf("trueColor %v: line %d = %q, want %q", trueColor, i+1, plain, want[i])
+			}
+			if strings.Contains(line, "\x1b[") && !strings.HasSuffix(line, "\x1b[0m") {
+				t.Errorf("trueColor %v: line %d = %q does not reset its colours", trueColor, i+1, line)
+			}
+		}
+		colour := "\x1b[38;5;"
+		if trueColor {
+			colour = "\x1b[38;2;"
+		}
+		if !strings.Contains(lines[0], colour) {
+			t.Errorf("trueColor %v: line 1 = %q, want %q colours", trueColor, lines[0], colour)
+		}
+	}
+
+	h, _ := NewHighlighter("monokai", 16, false)
+	if lines := h.Lines("main.go", source); lines != nil {
+		t.Errorf("a file over the size limit was highlighted: %q", lines)
+	}
+	if lines := h.Lines("notes.unknown-ext", "text\n"); lines != nil {
+		t.Errorf("a file of unknown syntax was highlighted: %q", lines)
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	tests := []struct {
+		r, g, b uint8
+		want    int
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{255, 0, 0, 196},
+		{0, 95, 135, 24},
+		{128, 128, 128, 244}, // Grey ramp
+		{250, 130, 10, 208},
+	}
+	for _, tt := range tests {
+		if got := xterm256(tt.r, tt.g, tt.b); got != tt.want {
+			t.Errorf("xterm256(%d, %d, %d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyTimeLayout starts history file names, so they sort by time. It has
+// milliseconds so exports in quick succession get their own files.
+const historyTimeLayout = "20060102-150405.000"
+
+// historyMeta describes an exported bundle. It is written as "key: value"
+// lines in an HTML comment at the top of the export, hidden when the
+// Markdown is rendered.
+type historyMeta struct {
+	Created          time.Time
+	Roots            []string
+	Branch           string
+	Commit           string
+	Format           string // How the bundle was rendered, e.g. "plain, line numbers"
+	Files            []historyFile
+	Chars            int
+	Tokens           int // 0 if the tokenizer was unavailable
+	Instruction      string
+	InstructionBytes int // Length of the instruction text within the body
+}
+
+// historyFile is one bundled file listed in the metadata.
+type historyFile struct {
+	Path   string
+	Tokens int
+}
+
+// historyEntry is an export found in the history directory.
+type historyEntry struct {
+	path string
+	meta historyMeta
+	err  error // Set if the metadata could not be read
+}
+
+// getHistoryDir returns the directory of exported bundles,
+// $XDG_DATA_HOME/grepforllm/history (~/.local/share/grepforllm/history),
+// creating it if needed.
+func getHistoryDir() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+
+	historyDir := filepath.Join(dataDir, "grepforllm", "history")
+	if err := os.MkdirAll(historyDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create history directory %s: %w", historyDir, err)
+	}
+	return historyDir, nil
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// historyFileName names the export of a bundle from roots created at t.
+func historyFileName(t time.Time, roots []string) string {
+	repo := "bundle"
+	if len(roots) > 0 {
+		repo = unsafeFileNameChars.ReplaceAllString(filepath.Base(roots[0]), "_")
+	}
+	if len(roots) > 1 {
+		repo += fmt.Sprintf("+%d", len(roots)-1)
+	}
+	return t.Format(historyTimeLayout) + "-" + repo + ".md"
+}
+
+// writeHistoryFile writes an export: the metadata comment, then body exactly
+// as it was copied. With unique set an existing file is never replaced: a
+// numeric suffix is added to the name instead. It returns the path written.
+func writeHistoryFile(path string, meta historyMeta, body string, unique bool) (string, error) {
+	var b strings.Builder
+	b.WriteString("<!-- grepforllm bundle\n")
+	fmt.Fprintf(&b, "created: %s\n", meta.Created.Format(time.RFC3339))
+	for _, root := range meta.Roots {
+		fmt.Fprintf(&b, "root: %s\n", root)
+	}
+	if meta.Branch != "" {
+		fmt.Fprintf(&b, "branch: %s\n", meta.Branch)
+	}
+	if meta.Commit != "" {
+		fmt.Fprintf(&b, "commit: %s\n", meta.Commit)
+	}
+	fmt.Fprintf(&b, "format: %s\n", meta.Format)
+	fmt.Fprintf(&b, "chars: %d\n", meta.Chars)
+	fmt.Fprintf(&b, "tokens: %d\n", meta.Tokens)
+	if meta.Instruction != "" {
+		fmt.Fprintf(&b, "instruction: %s, %d bytes\n", meta.Instruction, meta.InstructionBytes)
+	}
+	for _, file := range meta.Files {
+		fmt.Fprintf(&b, "file: %d %s\n", file.Tokens, file.Path)
+	}
+	b.WriteString("-->\n")
+	b.WriteString(body)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+	if !unique {
+		return path, os.WriteFile(path, []byte(b.String()), 0o600)
+	}
+
+	base := strings.TrimSuffix(path, ".md")
+	for i := 1; ; i++ {
+		if i > 1 {
+			path = fmt.Sprintf("%s-%d.md", base, i)
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(b.String())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return path, err
+	}
+}
+
+// readHistoryFile reads an export written by writeHistoryFile. With withBody
+// false only the metadata is read.
+func readHistoryFile(path string, withBody bool) (historyMeta, string, error) {
+	var meta historyMeta
+	f, err := os.Open(path)
+	if err != nil {
+		return meta, "", err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	first, err := r.ReadString('\n')
+	if err != nil || first != "<!-- grepforllm bundle\n" {
+		return meta, "", fmt.Errorf("%s is not a grepforllm export", filepath.Base(path))
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return meta, "", fmt.Errorf("%s: unterminated metadata", filepath.Base(path))
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "-->" {
+			break
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		switch key {
+		case "created":
+			meta.Created, _ = time.Parse(time.RFC3339, value)
+		case "root":
+			meta.Roots = append(meta.Roots, value)
+		case "branch":
+			meta.Branch = value
+		case "commit":
+			meta.Commit = value
+		case "format":
+			meta.Format = value
+		case "chars":
+			meta.Chars, _ = strconv.Atoi(value)
+		case "tokens":
+			meta.Tokens, _ = strconv.Atoi(value)
+		case "instruction":
+			position, size, _ := strings.Cut(value, ", ")
+			meta.Instruction = position
+			meta.InstructionBytes, _ = strconv.Atoi(strings.TrimSuffix(size, " bytes"))
+		case "file":
+			tokens, path, _ := strings.Cut(value, " ")
+			n, _ := strconv.Atoi(tokens)
+			meta.Files = append(meta.Files, historyFile{Path: path, Tokens: n})
+		}
+	}
+	if !withBody {
+		return meta, "", nil
+	}
+
+	var body strings.Builder
+	if _, err := r.WriteTo(&body); err != nil {
+		return meta, "", err
+	}
+	return meta, body.String(), nil
+}
+
+// listHistory returns the exports in the history directory, newest first.
+func listHistory() ([]historyEntry, error) {
+	historyDir, err := getHistoryDir()
+	if err != nil {
+		return nil, err
+	}
+	dirEntries, err := os.ReadDir(historyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []historyEntry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".md") {
+			continue
+		}
+		path := filepath.Join(historyDir, dirEntry.Name())
+		meta, _, err := readHistoryFile(path, false)
+		entries = append(entries, historyEntry{path: path, meta: meta, err: err})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return filepath.Base(entries[i].path) > filepath.Base(entries[j].path)
+	})
+	return entries, nil
+}
+
+// bundleFormat describes the current bundle options for export metadata.
+// Assumes mutex is held.
+func (app *App) bundleFormat() string {
+	parts := []string{"plain"}
+	if app.lineNumbers {
+		parts = append(parts, "line numbers")
+	}
+	if app.transforms.Any() {
+		parts = append(parts, "transforms "+app.transforms.String())
+	}
+	skeletons := 0
+	for relPath, mode := range app.renderModes {
+		if mode == RenderSkeleton && app.selectedFiles[relPath] {
+			skeletons++
+		}
+	}
+	if skeletons > 0 {
+		parts = append(parts, fmt.Sprintf("%d skeleton(s)", skeletons))
+	}
+	if app.truncation.Active() {
+		parts = append(parts, "truncate "+app.truncation.String())
+	}
+	overrides := 0
+	for relPath := range app.truncations {
+		if app.selectedFiles[relPath] {
+			overrides++
+		}
+	}
+	if overrides > 0 {
+		parts = append(parts, fmt.Sprintf("%d truncation override(s)", overrides))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// exportBundle writes the selected files' bundle, as CopyAllSelected would
+// copy it, to path, or to a new file in the history directory if path is "".
+// It returns the path written and the bundle.
+func (app *App) exportBundle(path string) (string, bundle, error) {
+	app.mutex.Lock()
+	selection := app.selectionOrder()
+	format := app.bundleFormat()
+	tokenizer := app.tokenizer
+	instruction := app.instruction
+	after := app.instructionAfter
+	app.mutex.Unlock()
+	roots := app.RootDirs() // Roots never change after NewApp
+
+	result := app.buildBundle(app.inBundleOrder(selection))
+	body := app.withInstruction(result.content)
+
+	meta := historyMeta{Created: time.Now(), Roots: roots, Format: format, Chars: len(body)}
+	if len(roots) > 0 {
+		meta.Branch = gitBranch(roots[0])
+		meta.Commit = gitCommit(roots[0])
+	}
+	if instruction != "" {
+		meta.Instruction = "before"
+		if after {
+			meta.Instruction = "after"
+		}
+		meta.InstructionBytes = len(body) - len(result.content)
+	}
+	for _, entry := range result.entries {
+		file := historyFile{Path: entry.path}
+		if tokenizer != nil {
+			file.Tokens = len(tokenizer.Encode(entry.content, nil, nil))
+		}
+		meta.Files = append(meta.Files, file)
+	}
+	if tokenizer != nil {
+		meta.Tokens = len(tokenizer.Encode(body, nil, nil))
+	}
+
+	unique := path == "" // A path typed in the prompt is the user's to replace
+	if unique {
+		historyDir, err := getHistoryDir()
+		if err != nil {
+			return "", result, err
+		}
+		path = filepath.Join(historyDir, historyFileName(meta.Created, roots))
+	}
+	path, err := writeHistoryFile(path, meta, body, unique)
+	if err != nil {
+		return "", result, err
+	}
+	return path, result, nil
+}
+
+// historyBlocks splits the file part of an export's body into the bundle
+// blocks of the files listed in meta, keyed by path.
+func historyBlocks(meta historyMeta, body string) map[string]string {
+	switch meta.Instruction {
+	case "before":
+		body = body[min(meta.InstructionBytes, len(body)):]
+	case "after":
+		body = body[:max(len(body)-meta.InstructionBytes, 0)]
+	}
+
+	blocks := make(map[string]string)
+	lines := strings.SplitAfter(body, "\n")
+	separator := strings.SplitAfter(fileSeparator("x"), "\n")[0] // The ==== line
+
+	current := ""
+	var b strings.Builder
+	flush := func() {
+		if current != "" {
+			blocks[current] += b.String()
+		}
+		b.Reset()
+	}
+	for i := 0; i < len(lines); i++ {
+		if lines[i] == separator && i+2 < len(lines) && lines[i+2] == separator && strings.HasPrefix(lines[i+1], "FILE: ") {
+			header := strings.TrimSuffix(strings.TrimPrefix(lines[i+1], "FILE: "), "\n")
+			for _, file := range meta.Files {
+				if header == file.Path || strings.HasPrefix(header, file.Path+" (") {
+					flush()
+					current = file.Path
+					break
+				}
+			}
+		}
+		b.WriteString(lines[i])
+	}
+	flush()
+	return blocks
+}
+
+// diffHistory compares an export with the selected files' current bundle
+// blocks and renders a summary followed by a unified diff per changed file.
+func (app *App) diffHistory(entry historyEntry) (string, error) {
+	meta, body, err := readHistoryFile(entry.path, true)
+	if err != nil {
+		return "", err
+	}
+	roots := app.RootDirs()
+	if strings.Join(meta.Roots, "\n") != strings.Join(roots, "\n") {
+		return "", fmt.Errorf("exported from %s, not the current directories", strings.Join(meta.Roots, ", "))
+	}
+
+	paths := make([]string, len(meta.Files))
+	for i, file := range meta.Files {
+		paths[i] = file.Path
+	}
+	old := historyBlocks(meta, body)
+	current := app.buildBundle(paths)
+
+	var summary, diffs []string
+	for _, entry := range current.entries {
+		oldBlock, ok := old[entry.path]
+		switch {
+		case !ok:
+			summary = append(summary, fmt.Sprintf("  ?  %s (not found in the export)", entry.path))
+		case entry.err != nil:
+			summary = append(summary, fmt.Sprintf("  -  %s (now unreadable: %v)", entry.path, entry.err))
+		case oldBlock == entry.block:
+			summary = append(summary, fmt.Sprintf("  =  %s", entry.path))
+		default:
+			ops := diffLines(strings.SplitAfter(oldBlock, "\n"), strings.SplitAfter(entry.block, "\n"))
+			added, removed := diffStats(ops)
+			summary = append(summary, fmt.Sprintf("  ~  %s (+%d -%d)", entry.path, added, removed))
+			diffs = append(diffs, "", paint(app.theme.Heading, "--- "+entry.path+" (export)"), paint(app.theme.Heading, "+++ "+entry.path+" (now)"))
+			for _, line := range unifiedHunks(ops, 3) {
+				diffs = append(diffs, colorDiffLine(app.theme, line))
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %d file(s)", meta.Created.Local().Format("2006-01-02 15:04"), len(meta.Files))
+	if meta.Commit != "" {
+		fmt.Fprintf(&b, ", commit %.7s", meta.Commit)
+	}
+	if commit := gitCommit(roots[0]); commit != "" && commit != meta.Commit {
+		fmt.Fprintf(&b, " (now %.7s)", commit)
+	}
+	fmt.Fprintf(&b, "\nCompared with the files as bundled now (format then: %s)\n\n", meta.Format)
+	b.WriteString(strings.Join(summary, "\n"))
+	b.WriteString("\n")
+	for _, line := range diffs {
+		fmt.Fprintln(&b, line)
+	}
+	return b.String(), nil
+}
+
+// colorDiffLine colours a line of a unified diff hunk for display.
+func colorDiffLine(theme Theme, line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return paint(theme.DiffHunk, line)
+	case strings.HasPrefix(line, "+"):
+		return paint(theme.DiffAdded, line)
+	case strings.HasPrefix(line, "-"):
+		return paint(theme.DiffRemoved, line)
+	}
+	return line
+}
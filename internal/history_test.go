@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteHistoryFileUnique(t *testing.T) {
+	created := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), historyFileName(created, []string{"/src/my repo"}))
+	if want := "20260102-150405.000-my_repo.md"; filepath.Base(path) != want {
+		t.Fatalf("historyFileName = %s, want %s", filepath.Base(path), want)
+	}
+
+	meta := historyMeta{Created: created, Roots: []string{"/src/my repo"}, Format: "plain"}
+	var written []string
+	for _, body := range []string{"first\n", "second\n", "third\n"} {
+		got, err := writeHistoryFile(path, meta, body, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written = append(written, got)
+	}
+	want := []string{path, path[:len(path)-len(".md")] + "-2.md", path[:len(path)-len(".md")] + "-3.md"}
+	for i := range want {
+		if written[i] != want[i] {
+			t.Errorf("export %d written to %s, want %s", i+1, written[i], want[i])
+		}
+	}
+
+	_, body, err := readHistoryFile(path, true)
+	if err != nil || body != "first\n" {
+		t.Errorf("first export reads %q, %v; want it kept", body, err)
+	}
+
+	// Without unique the file is replaced, as for a path typed in the prompt.
+	if _, err := writeHistoryFile(path, meta, "replaced\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, body, _ := readHistoryFile(path, true); body != "replaced\n" {
+		t.Errorf("export reads %q, want it replaced", body)
+	}
+}
@@ -0,0 +1,549 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Keymap maps action names to the keys that trigger them. A plain name
+// ("copy") overrides the defaults of every view the action exists in; a name
+// prefixed with a view ("files.close") overrides one view only, and wins
+// over the plain name. It is read from ~/.config/grepforllm/keymap.json, e.g.
+//
+//	{"show_cache": "ctrl+k", "copy": ["c", "y", "ctrl+y"], "history.close": "esc"}
+//
+// An empty list unbinds the action.
+type Keymap map[string][]string
+
+// keyBinding is an action in one view ("" for global) and its default keys.
+type keyBinding struct {
+	view     string
+	action   string
+	defaults []string
+	help     string
+	handler  func(*gocui.Gui, *gocui.View) error
+}
+
+// boundKey is a parsed key: a gocui.Key or a rune, and its label in the Help
+// view.
+type boundKey struct {
+	key   interface{}
+	label string
+}
+
+// activeBinding is a keyBinding with the keys it is bound to after applying
+// the keymap.
+type activeBinding struct {
+	keyBinding
+	keys []boundKey
+}
+
+// keymapViews are the view prefixes of view-specific keymap names.
+var keymapViews = map[string]string{
+	"":                   "global",
+	FilesViewName:        "files",
+	ContentViewName:      "content",
+	FilterViewName:       "filter",
+	CacheViewName:        "cache",
+	SkippedViewName:      "skipped",
+	OrderViewName:        "order",
+	HistoryViewName:      "history",
+	CopyPreviewViewName:  "copy_preview",
+	TemplatesViewName:    "templates",
+	TransformsViewName:   "transforms",
+	RefsPromptViewName:   "refs_prompt",
+	ReferencesViewName:   "references",
+	ExportPromptViewName: "export_prompt",
+	TruncPromptViewName:  "trunc_prompt",
+	InstructionViewName:  "instruction",
+	HelpViewName:         "help",
+}
+
+// keymapName returns the view-specific keymap name of b, e.g. "files.close".
+func (b keyBinding) keymapName() string {
+	return keymapViews[b.view] + "." + b.action
+}
+
+// editableViews take text input, so printable keys cannot be bound in them.
+var editableViews = map[string]bool{
+	FilterViewName:       true,
+	RefsPromptViewName:   true,
+	ExportPromptViewName: true,
+	TruncPromptViewName:  true,
+	InstructionViewName:  true,
+}
+
+// namedKeys are the keys with names, besides ctrl+<letter> and single
+// characters.
+var namedKeys = map[string]boundKey{
+	"enter":     {gocui.KeyEnter, "Enter"},
+	"esc":       {gocui.KeyEsc, "Esc"},
+	"tab":       {gocui.KeyTab, "Tab"},
+	"space":     {gocui.KeySpace, "Space"},
+	"backspace": {gocui.KeyBackspace2, "Backspace"},
+	"delete":    {gocui.KeyDelete, "Delete"},
+	"insert":    {gocui.KeyInsert, "Insert"},
+	"home":      {gocui.KeyHome, "Home"},
+	"end":       {gocui.KeyEnd, "End"},
+	"pgup":      {gocui.KeyPgup, "PgUp"},
+	"pgdn":      {gocui.KeyPgdn, "PgDn"},
+	"up":        {gocui.KeyArrowUp, "↑"},
+	"down":      {gocui.KeyArrowDown, "↓"},
+	"left":      {gocui.KeyArrowLeft, "←"},
+	"right":     {gocui.KeyArrowRight, "→"},
+	"f1":        {gocui.KeyF1, "F1"},
+	"f2":        {gocui.KeyF2, "F2"},
+	"f3":        {gocui.KeyF3, "F3"},
+	"f4":        {gocui.KeyF4, "F4"},
+	"f5":        {gocui.KeyF5, "F5"},
+	"f6":        {gocui.KeyF6, "F6"},
+	"f7":        {gocui.KeyF7, "F7"},
+	"f8":        {gocui.KeyF8, "F8"},
+	"f9":        {gocui.KeyF9, "F9"},
+	"f10":       {gocui.KeyF10, "F10"},
+	"f11":       {gocui.KeyF11, "F11"},
+	"f12":       {gocui.KeyF12, "F12"},
+}
+
+// parseKey parses a key name: a single character ("c", "?", "K"), a named
+// key ("enter", "pgup", "up") or ctrl+<letter>.
+func parseKey(name string) (boundKey, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		if r == ' ' {
+			return namedKeys["space"], nil
+		}
+		return boundKey{r, name}, nil
+	}
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if key, ok := namedKeys[lower]; ok {
+		return key, nil
+	}
+	if letter, ok := strings.CutPrefix(lower, "ctrl+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return boundKey{gocui.KeyCtrlA + gocui.Key(letter[0]-'a'), "Ctrl+" + strings.ToUpper(letter)}, nil
+	}
+	return boundKey{}, fmt.Errorf("unknown key %q (expected a character, ctrl+<letter>, enter, esc, tab, space, backspace, delete, insert, home, end, pgup, pgdn, up, down, left, right or f1-f12)", name)
+}
+
+// getKeymapFilePath determines the path of the keymap file.
+func getKeymapFilePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "keymap.json"), nil // ~/.config/grepforllm/keymap.json
+}
+
+// LoadKeymap reads the keymap file. A missing file yields no overrides.
+// Each action maps to a key or a list of keys.
+func LoadKeymap() (Keymap, error) {
+	path, err := getKeymapFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keymap file %s: %w", path, err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse keymap file %s: %w", path, err)
+	}
+	keymap := make(Keymap, len(raw))
+	for action, value := range raw {
+		var keys []string
+		var key string
+		if err := json.Unmarshal(value, &key); err == nil {
+			keys = []string{key}
+		} else if err := json.Unmarshal(value, &keys); err != nil {
+			return nil, fmt.Errorf("keymap file %s: %s must be a key or a list of keys", path, action)
+		}
+		keymap[action] = keys
+	}
+	return keymap, nil
+}
+
+// SetKeymap applies keymap to the default bindings. Views get their
+// bindings from the active keymap in SetKeybindings.
+func (app *App) SetKeymap(keymap Keymap) error {
+	active, err := applyKeymap(app.defaultBindings(), keymap)
+	if err != nil {
+		return err
+	}
+	app.mutex.Lock()
+	app.bindings = active
+	app.mutex.Unlock()
+	return nil
+}
+
+// applyKeymap resolves the keys of bindings under keymap. It fails on
+// unknown actions or keys and on conflicts: a key bound to two actions of
+// one view, a printable key in a view taking text input, or a view key
+// hiding a global one where the defaults do not. A plain name only applies
+// the keys that fit each view, and fails if none does.
+func applyKeymap(bindings []keyBinding, keymap Keymap) ([]activeBinding, error) {
+	known := make(map[string]bool)
+	for _, b := range bindings {
+		known[b.action] = true
+		known[b.keymapName()] = true
+	}
+	var unknown []string
+	for action := range keymap {
+		if !known[action] {
+			unknown = append(unknown, action)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("keymap: unknown action(s): %s", strings.Join(unknown, ", "))
+	}
+
+	// View bindings take precedence over global ones. The defaults hide some
+	// on purpose (q closes most views).
+	defaultGlobals := make(map[interface{}]string)
+	shippedShadows := make(map[string]bool)
+	for _, b := range bindings {
+		for _, name := range b.defaults {
+			key, _ := parseKey(name) // The defaults are valid
+			if b.view == "" {
+				defaultGlobals[key.key] = b.action
+			} else if global, ok := defaultGlobals[key.key]; ok {
+				shippedShadows[shadowID(b, key, global)] = true
+			}
+		}
+	}
+
+	active := make([]activeBinding, 0, len(bindings))
+	owners := make(map[string]map[interface{}]string) // view -> key -> action
+	var conflicts []string
+	for _, b := range bindings { // Global bindings come first
+		name, names, shared := b.action, b.defaults, false
+		if override, ok := keymap[b.action]; ok {
+			names, shared = override, true
+		}
+		if override, ok := keymap[b.keymapName()]; ok {
+			name, names, shared = b.keymapName(), override, false
+		}
+		if owners[b.view] == nil {
+			owners[b.view] = make(map[interface{}]string)
+		}
+
+		ab := activeBinding{keyBinding: b}
+		var unfit string // Why the last key of a plain name was left out
+		for _, keyName := range names {
+			key, err := parseKey(keyName)
+			if err != nil {
+				return nil, fmt.Errorf("keymap: %s: %w", name, err)
+			}
+			_, printable := key.key.(rune)
+			if printable && editableViews[b.view] {
+				unfit = fmt.Sprintf("%q would be typed into the %s", keyName, viewTitle(b.view))
+				if shared {
+					continue
+				}
+				return nil, fmt.Errorf("keymap: %s: %s, use a special or ctrl key", name, unfit)
+			}
+			if global, ok := owners[""][key.key]; ok && b.view != "" && global != b.action && !shippedShadows[shadowID(b, key, global)] {
+				unfit = fmt.Sprintf("%s is bound to %s in the %s, hiding the global %s", key.label, b.action, viewTitle(b.view), global)
+				if !shared {
+					conflicts = append(conflicts, unfit)
+				}
+				continue
+			}
+			if owner, taken := owners[b.view][key.key]; taken && owner != b.action {
+				conflicts = append(conflicts, fmt.Sprintf("%s is bound to both %s and %s in the %s", key.label, owner, b.action, viewTitle(b.view)))
+				continue
+			}
+			owners[b.view][key.key] = b.action
+			ab.keys = append(ab.keys, key)
+		}
+		if shared && len(names) > 0 && len(ab.keys) == 0 && unfit != "" {
+			return nil, fmt.Errorf("keymap: %s: no key fits the %s (%s); set %s", name, viewTitle(b.view), unfit, b.keymapName())
+		}
+		active = append(active, ab)
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("keymap: conflicting keys:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+	return active, nil
+}
+
+// shadowID identifies key bound to b's action in b's view hiding the global
+// action, to tell the global keys the defaults hide from those a keymap
+// hides.
+func shadowID(b keyBinding, key boundKey, global string) string {
+	return fmt.Sprintf("%s\x00%s\x00%v\x00%s", b.view, b.action, key.key, global)
+}
+
+// activeBindings returns the bindings of the active keymap, the defaults if
+// SetKeymap was not called.
+func (app *App) activeBindings() []activeBinding {
+	app.mutex.Lock()
+	bindings := app.bindings
+	app.mutex.Unlock()
+	if bindings == nil {
+		_ = app.SetKeymap(nil) // The defaults are valid
+		app.mutex.Lock()
+		bindings = app.bindings
+		app.mutex.Unlock()
+	}
+	return bindings
+}
+
+// keyHint returns the label of the first key bound to action in view, for
+// view titles, or "" if the action is unbound.
+func (app *App) keyHint(view, action string) string {
+	for _, b := range app.activeBindings() {
+		if b.view == view && b.action == action && len(b.keys) > 0 {
+			return b.keys[0].label
+		}
+	}
+	return ""
+}
+
+// keyHints joins the first keys of actions in view with "/", for hints
+// naming several keys, e.g. "c/Enter".
+func (app *App) keyHints(view string, actions ...string) string {
+	var hints []string
+	for _, action := range actions {
+		if hint := app.keyHint(view, action); hint != "" {
+			hints = append(hints, hint)
+		}
+	}
+	return strings.Join(hints, "/")
+}
+
+// helpSections are the sections of the Help view, in order, with notes
+// printed below their bindings.
+var helpSections = []struct {
+	title string
+	view  string
+	notes []string
+}{
+	{"General", "", []string{
+		"Mouse: click a file to preview it, its [ ] to select it, a view to focus it;",
+		" the wheel scrolls; drag the border left of the Content view to resize",
+		" (--no-mouse turns the mouse off, e.g. to select text in the terminal)",
+	}},
+	{"Files View (Left)", FilesViewName, []string{
+		"(name@ marks a file reached through a symlink, see --symlinks;",
+		" name {...} marks a file bundled as a Go skeleton;",
+		" name [head 200] marks a file cut down by a truncation rule;",
+		" name [!] marks .env*/*.pem files: select twice to include)",
+		"Secrets (keys, tokens, passwords) are redacted from copied bundles.",
+	}},
+	{"Content View (Right)", ContentViewName, nil},
+	{"Filter View (Bottom-Left)", FilterViewName, []string{"(Type patterns: *.go, cmd/, file.txt)"}},
+	{"Cache View", CacheViewName, nil},
+	{"Skipped Files View", SkippedViewName, nil},
+	{"Order View", OrderViewName, nil},
+	{"History View", HistoryViewName, nil},
+	{"Copy Preview", CopyPreviewViewName, nil},
+	{"Template Picker", TemplatesViewName, nil},
+	{"Transforms", TransformsViewName, []string{"1-9: Toggle the numbered transform"}},
+	{"References", ReferencesViewName, nil},
+	{"References Prompt", RefsPromptViewName, nil},
+	{"Export Prompt", ExportPromptViewName, nil},
+	{"Truncation Prompt", TruncPromptViewName, nil},
+	{"Instruction Editor", InstructionViewName, nil},
+	{"Help", HelpViewName, nil},
+}
+
+// viewTitle names view in keymap errors, e.g. "Files View".
+func viewTitle(view string) string {
+	if view == "" {
+		return "global bindings"
+	}
+	for _, section := range helpSections {
+		if section.view == view {
+			title, _, _ := strings.Cut(section.title, " (")
+			return title
+		}
+	}
+	return view + " view"
+}
+
+// writeHelp writes the Help view text generated from the active keymap.
+func (app *App) writeHelp(w *gocui.View) {
+	bindings := app.activeBindings()
+	fmt.Fprintln(w, "grepforllm - Select & Copy File Contents")
+	fmt.Fprintln(w, "----------------------------------------")
+	fmt.Fprintln(w, "Keys can be changed in ~/.config/grepforllm/keymap.json (action names in brackets;")
+	fmt.Fprintln(w, "a name applies to every view that has it, files.close to the Files view only).")
+	for _, section := range helpSections {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		for _, b := range bindings {
+			if b.view != section.view {
+				continue
+			}
+			labels := make([]string, len(b.keys))
+			for i, key := range b.keys {
+				labels[i] = key.label
+			}
+			keys := strings.Join(labels, " / ")
+			if keys == "" {
+				keys = "(unbound)"
+			}
+			fmt.Fprintf(w, "  %-13s : %s [%s]\n", keys, b.help, b.action)
+		}
+		for _, note := range section.notes {
+			fmt.Fprintf(w, "  %s\n", note)
+		}
+	}
+}
+
+// defaultBindings lists every bindable action with its default keys.
+func (app *App) defaultBindings() []keyBinding {
+	return []keyBinding{
+		// --- Global ---
+		{"", "force_quit", []string{"ctrl+q"}, "Force quit", quit},
+		{"", "show_cache", []string{"ctrl+c"}, "Show the cache view", app.ShowCacheView},
+		{"", "quit", []string{"q"}, "Quit / close help / close cache", app.QuitHandler},
+		{"", "toggle_help", []string{"?"}, "Toggle this help", app.ToggleHelp},
+		{"", "switch_focus", []string{"tab"}, "Switch focus Files <-> Filter <-> Content", app.SwitchFocus},
+		{"", "page_up", []string{"pgup", "ctrl+b"}, "Scroll content up one page", app.ScrollContentUp},
+		{"", "page_down", []string{"pgdn"}, "Scroll content down one page", app.ScrollContentDown},
+
+		// --- Files View ---
+		{FilesViewName, "cursor_up", []string{"up", "k"}, "Move cursor up", app.CursorUp},
+		{FilesViewName, "cursor_down", []string{"down", "j"}, "Move cursor down", app.CursorDown},
+		{FilesViewName, "focus_content", []string{"enter"}, "Focus the content view for scrolling", app.FocusContentView},
+		{FilesViewName, "toggle_select", []string{"space"}, "Toggle select file under cursor ([~] = line ranges only)", app.ToggleSelect},
+		{FilesViewName, "select_all", []string{"a"}, "Select / deselect all visible files", app.SelectAllFiles},
+		{FilesViewName, "copy", []string{"c", "y"}, "Copy selected files (falls back to tmux, OSC 52, --clipboard-command, a file)", app.CopyAllSelected},
+		{FilesViewName, "preview_copy", []string{"v"}, "Preview exactly what copy would copy", app.ShowCopyPreview},
+		{FilesViewName, "export", []string{"e"}, "Export the bundle to the history (~/.local/share/grepforllm/history)", app.ExportBundle},
+		{FilesViewName, "export_to", []string{"E"}, "Export the bundle to a path", app.PromptExportPath},
+		{FilesViewName, "history", []string{"H"}, "Browse exported bundles: copy again or diff with the current files", app.ShowHistoryView},
+		{FilesViewName, "copy_next_part", []string{"]"}, "Copy the next part of a bundle split by --split-tokens/--split-bytes", app.CopyNextPart},
+		{FilesViewName, "copy_previous_part", []string{"["}, "Copy the previous part of a split bundle", app.CopyPreviousPart},
+		{FilesViewName, "reorder", []string{"o"}, "Reorder the selected files (* = moved by hand)", app.ShowOrderView},
+		{FilesViewName, "cycle_order", []string{"O"}, "Cycle bundle order: alphabetical, dependency, mtime, tests last", app.CycleOrderStrategy},
+		{FilesViewName, "templates", []string{"P"}, "Copy through a prompt template (*.tmpl in ~/.config/grepforllm/templates or .grepforllm/templates)", app.ShowTemplatesView},
+		{FilesViewName, "instruction", []string{"i"}, "Edit the instruction copied with the files", app.ShowInstructionView},
+		{FilesViewName, "show_skipped", []string{"x"}, "Show skipped files (why a file is missing)", app.ShowSkippedView},
+		{FilesViewName, "toggle_line_numbers", []string{"n"}, "Toggle line numbers in copied bundle and preview", app.ToggleLineNumbers},
+		{FilesViewName, "transforms", []string{"t"}, "Choose content transforms (strip comments, blank lines, ...)", app.ToggleTransformsView},
+		{FilesViewName, "toggle_skeleton", []string{"s"}, "Toggle Go skeleton mode: signatures only, bodies { ... }", app.ToggleSkeleton},
+		{FilesViewName, "truncate", []string{"T"}, "Truncate the file: head:N, tail:N, headtail:N, tokens:N, none or empty for --truncate", app.PromptTruncation},
+		{FilesViewName, "expand_package", []string{"g"}, "Select all files of the selected Go files' packages", app.ExpandGoPackage},
+		{FilesViewName, "expand_imports", []string{"G"}, "Like expand_package, plus in-module imported packages (see --import-depth)", app.ExpandGoImports},
+		{FilesViewName, "find_references", []string{"r"}, "Select files referencing an identifier or pkg.Name (listed first)", app.PromptReferences},
+		{FilesViewName, "grow_files", []string{">"}, "Widen the Files pane (taller when stacked)", app.GrowFilesPane},
+		{FilesViewName, "shrink_files", []string{"<"}, "Narrow the Files pane (shorter when stacked)", app.ShrinkFilesPane},
+		{FilesViewName, "toggle_preview", []string{"z"}, "Hide / show the Content view", app.TogglePreview},
+		{FilesViewName, "cycle_layout", []string{"L"}, "Cycle layout: auto (stacked on narrow terminals), side by side, stacked", app.CycleLayout},
+
+		// --- Content View ---
+		{ContentViewName, "cursor_up", []string{"up", "k"}, "Scroll up one line", app.ScrollContentLineUp},
+		{ContentViewName, "cursor_down", []string{"down", "j"}, "Scroll down one line", app.ScrollContentLineDown},
+		{ContentViewName, "mark_lines", []string{"v"}, "Start marking lines / mark range (partial selection)", app.ToggleVisualMode},
+		{ContentViewName, "clear_ranges", []string{"u"}, "Clear marked ranges of this file", app.ClearLineRanges},
+		{ContentViewName, "toggle_line_numbers", []string{"n"}, "Toggle line numbers", app.ToggleLineNumbers},
+		{ContentViewName, "toggle_preview_transformed", []string{"p"}, "Preview raw file / file as bundled (transforms, skeleton)", app.TogglePreviewTransformed},
+		{ContentViewName, "find_references", []string{"r"}, "Find references to the identifier on the top line", app.PromptReferences},
+		{ContentViewName, "grow_files", []string{">"}, "Widen the Files pane (taller when stacked)", app.GrowFilesPane},
+		{ContentViewName, "shrink_files", []string{"<"}, "Narrow the Files pane (shorter when stacked)", app.ShrinkFilesPane},
+		{ContentViewName, "toggle_preview", []string{"z"}, "Hide the Content view", app.TogglePreview},
+		{ContentViewName, "cycle_layout", []string{"L"}, "Cycle layout: auto, side by side, stacked", app.CycleLayout},
+		{ContentViewName, "close", []string{"esc"}, "Cancel marking / return focus to Files", app.CancelVisualMode},
+
+		// --- Filter View ---
+		{FilterViewName, "confirm", []string{"enter"}, "Apply filter & return focus to Files", app.ApplyFilter},
+		{FilterViewName, "close", []string{"esc"}, "Cancel input & return focus to Files", app.CancelFilter},
+		{FilterViewName, "toggle_filter_mode", []string{"ctrl+f"}, "Toggle filter mode (Include/Exclude)", app.ToggleFilterMode},
+
+		// --- Cache View ---
+		{CacheViewName, "cursor_up", []string{"up", "k"}, "Scroll up one line", app.ScrollCacheViewUp},
+		{CacheViewName, "cursor_down", []string{"down", "j"}, "Scroll down one line", app.ScrollCacheViewDown},
+		{CacheViewName, "page_up", []string{"pgup"}, "Scroll up one page", app.ScrollCacheViewPageUp},
+		{CacheViewName, "page_down", []string{"pgdn"}, "Scroll down one page", app.ScrollCacheViewPageDown},
+		{CacheViewName, "clear_cache", []string{"ctrl+d"}, "Prompt to clear the cache", app.PromptClearCache},
+		{CacheViewName, "confirm_clear", []string{"y"}, "Confirm clearing the cache", app.ConfirmClearCache},
+		{CacheViewName, "cancel_clear", []string{"n"}, "Cancel clearing the cache", app.CancelClearCache},
+		{CacheViewName, "close", []string{"esc", "q"}, "Close the cache view", app.CloseCacheView},
+
+		// --- Skipped Files View ---
+		{SkippedViewName, "cursor_up", []string{"up", "k"}, "Move cursor up", app.SkippedCursorUp},
+		{SkippedViewName, "cursor_down", []string{"down", "j"}, "Move cursor down", app.SkippedCursorDown},
+		{SkippedViewName, "page_up", []string{"pgup"}, "Move cursor up one page", app.SkippedPageUp},
+		{SkippedViewName, "page_down", []string{"pgdn"}, "Move cursor down one page", app.SkippedPageDown},
+		{SkippedViewName, "toggle_select", []string{"space"}, "Toggle force-include of the file, or list the files of the directory, under cursor", app.ToggleForceInclude},
+		{SkippedViewName, "confirm", []string{"enter"}, "Toggle force-include of the file, or list the files of the directory, under cursor", app.ToggleForceInclude},
+		{SkippedViewName, "close", []string{"esc", "q"}, "Close the skipped files view", app.CloseSkippedView},
+
+		// --- Order View ---
+		{OrderViewName, "cursor_up", []string{"up", "k"}, "Move cursor up", app.OrderCursorUp},
+		{OrderViewName, "cursor_down", []string{"down", "j"}, "Move cursor down", app.OrderCursorDown},
+		{OrderViewName, "move_up", []string{"K"}, "Move the file up in the bundle", app.MoveFileUp},
+		{OrderViewName, "move_down", []string{"J"}, "Move the file down in the bundle", app.MoveFileDown},
+		{OrderViewName, "cycle_order", []string{"O"}, "Cycle the order strategy (unpins the files moved by hand)", app.CycleOrderStrategy},
+		{OrderViewName, "reset_order", []string{"r"}, "Unpin the files moved by hand", app.ResetOrder},
+		{OrderViewName, "reorder", []string{"o"}, "Close the order view", app.CloseOrderView},
+		{OrderViewName, "close", []string{"esc", "q"}, "Close the order view", app.CloseOrderView},
+
+		// --- History View ---
+		{HistoryViewName, "cursor_up", []string{"up", "k"}, "Move cursor up / scroll a diff", app.HistoryCursorUp},
+		{HistoryViewName, "cursor_down", []string{"down", "j"}, "Move cursor down / scroll a diff", app.HistoryCursorDown},
+		{HistoryViewName, "confirm", []string{"enter"}, "Copy the exported bundle again", app.RecopyHistoryEntry},
+		{HistoryViewName, "copy", []string{"c", "y"}, "Copy the exported bundle again", app.RecopyHistoryEntry},
+		{HistoryViewName, "diff", []string{"d"}, "Diff the exported files with the current ones", app.DiffHistoryEntry},
+		{HistoryViewName, "close", []string{"esc", "q"}, "Leave a diff / close the history", app.CloseHistoryView},
+
+		// --- Copy Preview ---
+		{CopyPreviewViewName, "cursor_up", []string{"up", "k"}, "Scroll up one line", app.ScrollPreviewLineUp},
+		{CopyPreviewViewName, "cursor_down", []string{"down", "j"}, "Scroll down one line", app.ScrollPreviewLineDown},
+		{CopyPreviewViewName, "page_up", []string{"pgup", "ctrl+b"}, "Scroll up one page", app.ScrollPreviewPageUp},
+		{CopyPreviewViewName, "page_down", []string{"pgdn", "space"}, "Scroll down one page", app.ScrollPreviewPageDown},
+		{CopyPreviewViewName, "top", []string{"g"}, "Jump to the start", app.ScrollPreviewTop},
+		{CopyPreviewViewName, "bottom", []string{"G"}, "Jump to the end", app.ScrollPreviewBottom},
+		{CopyPreviewViewName, "confirm", []string{"enter"}, "Copy the previewed bundle", app.ConfirmCopyPreview},
+		{CopyPreviewViewName, "copy", []string{"c", "y"}, "Copy the previewed bundle", app.ConfirmCopyPreview},
+		{CopyPreviewViewName, "close", []string{"esc", "q"}, "Close without copying", app.CloseCopyPreview},
+
+		// --- Template Picker ---
+		{TemplatesViewName, "cursor_up", []string{"up", "k"}, "Move cursor up", app.TemplatesCursorUp},
+		{TemplatesViewName, "cursor_down", []string{"down", "j"}, "Move cursor down", app.TemplatesCursorDown},
+		{TemplatesViewName, "confirm", []string{"enter"}, "Copy through the template", app.CopyWithTemplate},
+		{TemplatesViewName, "copy", []string{"c", "y"}, "Copy through the template", app.CopyWithTemplate},
+		{TemplatesViewName, "close", []string{"esc", "q"}, "Close the picker", app.CloseTemplatesView},
+
+		// --- Transforms ---
+		{TransformsViewName, "transforms", []string{"t"}, "Close the transforms", app.ToggleTransformsView},
+		{TransformsViewName, "close", []string{"esc", "q"}, "Close the transforms", app.ToggleTransformsView},
+
+		// --- References Prompt and View ---
+		{RefsPromptViewName, "confirm", []string{"enter"}, "Search references", app.SearchReferences},
+		{RefsPromptViewName, "close", []string{"esc"}, "Cancel", app.CloseReferences},
+		{ReferencesViewName, "cursor_up", []string{"up", "k"}, "Scroll up", app.ScrollReferencesUp},
+		{ReferencesViewName, "cursor_down", []string{"down", "j"}, "Scroll down", app.ScrollReferencesDown},
+		{ReferencesViewName, "confirm", []string{"enter", "y"}, "Select the referencing files", app.ConfirmReferences},
+		{ReferencesViewName, "cancel", []string{"n"}, "Cancel", app.CloseReferences},
+		{ReferencesViewName, "close", []string{"esc", "q"}, "Cancel", app.CloseReferences},
+
+		// --- Prompts and Editors ---
+		{ExportPromptViewName, "confirm", []string{"enter"}, "Export", app.ConfirmExportPath},
+		{ExportPromptViewName, "close", []string{"esc"}, "Cancel", app.CloseExportPrompt},
+		{TruncPromptViewName, "confirm", []string{"enter"}, "Apply the truncation", app.ConfirmTruncation},
+		{TruncPromptViewName, "close", []string{"esc"}, "Cancel", app.CloseTruncationPrompt},
+		{InstructionViewName, "toggle_instruction_position", []string{"ctrl+t"}, "Place the instruction before / after the files", app.ToggleInstructionPosition},
+		{InstructionViewName, "close", []string{"esc"}, "Save & close", app.CloseInstructionView},
+
+		// --- Help View ---
+		{HelpViewName, "cursor_up", []string{"up", "k"}, "Scroll up", app.ScrollHelpUp},
+		{HelpViewName, "cursor_down", []string{"down", "j"}, "Scroll down", app.ScrollHelpDown},
+		{HelpViewName, "toggle_help", []string{"?"}, "Close the help", app.ToggleHelp},
+		{HelpViewName, "close", []string{"esc", "q"}, "Close the help", app.ToggleHelp},
+	}
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyKeymap(t *testing.T) {
+	tests := []struct {
+		name    string
+		keymap  Keymap
+		wantErr string // Substring of the error, "" for success
+	}{
+		{"defaults", nil, ""},
+		{"empty", Keymap{}, ""},
+		{"shared close skips printable keys in prompts", Keymap{"close": {"esc", "q"}}, ""},
+		{"shared close without a fitting key", Keymap{"close": {"q"}}, "no key fits the Content View"},
+		{"view-specific close", Keymap{"files.copy": {"ctrl+y"}, "filter.close": {"ctrl+g"}}, ""},
+		{"printable key named for a prompt", Keymap{"filter.close": {"q"}}, `"q" would be typed into the Filter View`},
+		{"view key hiding a global key", Keymap{"copy": {"?"}}, "? is bound to copy in the Files View, hiding the global toggle_help"},
+		{"view-specific key hiding a global key", Keymap{"content.close": {"q"}}, "q is bound to close in the Content View, hiding the global quit"},
+		{"global key hidden by a default view key", Keymap{"quit": {"x"}}, "x is bound to show_skipped in the Files View, hiding the global quit"},
+		{"same view conflict", Keymap{"select_all": {"c"}}, "c is bound to both select_all and copy in the Files View"},
+		{"unknown action", Keymap{"frobnicate": {"f"}, "nowhere.copy": {"c"}}, "unknown action(s): frobnicate, nowhere.copy"},
+		{"unknown key", Keymap{"copy": {"ctrl+1"}}, `unknown key "ctrl+1"`},
+		{"unbind", Keymap{"copy": {}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyKeymap((&App{}).defaultBindings(), tt.keymap)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("expected an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyKeymapScope(t *testing.T) {
+	active, err := applyKeymap((&App{}).defaultBindings(), Keymap{
+		"confirm":       {"enter"},
+		"close":         {"esc", "q"},
+		"history.close": {"ctrl+w"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(view, action string) string {
+		for _, b := range active {
+			if b.view == view && b.action == action {
+				var labels []string
+				for _, key := range b.keys {
+					labels = append(labels, key.label)
+				}
+				return strings.Join(labels, " ")
+			}
+		}
+		t.Fatalf("no %s binding in %q", action, view)
+		return ""
+	}
+
+	tests := []struct {
+		view, action, want string
+	}{
+		{CacheViewName, "confirm_clear", "y"}, // Not a confirm action: unchanged
+		{ReferencesViewName, "confirm", "Enter"},
+		{FilterViewName, "close", "Esc"},  // q would be typed
+		{ContentViewName, "close", "Esc"}, // q would hide the global quit
+		{OrderViewName, "close", "Esc q"},
+		{HistoryViewName, "close", "Ctrl+W"}, // The view-specific name wins
+	}
+	for _, tt := range tests {
+		if got := keys(tt.view, tt.action); got != tt.want {
+			t.Errorf("%s in %s: got %q, want %q", tt.action, tt.view, got, tt.want)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name, label string
+		wantErr     bool
+	}{
+		{"c", "c", false},
+		{"?", "?", false},
+		{" ", "Space", false},
+		{"PgUp", "PgUp", false},
+		{"ctrl+k", "Ctrl+K", false},
+		{"Ctrl+K", "Ctrl+K", false},
+		{"ctrl+", "", true},
+		{"alt+x", "", true},
+	}
+	for _, tt := range tests {
+		key, err := parseKey(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseKey(%q): error %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && key.label != tt.label {
+			t.Errorf("parseKey(%q): label %q, want %q", tt.name, key.label, tt.label)
+		}
+	}
+}
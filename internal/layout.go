@@ -0,0 +1,132 @@
+package internal
+
+import "fmt"
+
+// LayoutMode arranges the Files and Content panes.
+type LayoutMode string
+
+const (
+	LayoutAuto    LayoutMode = "auto"    // Side by side, stacked on terminals narrower than the stack width (default)
+	LayoutSide    LayoutMode = "side"    // Files on the left, Content on the right
+	LayoutStacked LayoutMode = "stacked" // Files above, Content below
+)
+
+// layoutModes is the cycle order of CycleLayout.
+var layoutModes = []LayoutMode{LayoutAuto, LayoutSide, LayoutStacked}
+
+// ParseLayoutMode converts a cache value into a LayoutMode. The empty string
+// is auto.
+func ParseLayoutMode(s string) (LayoutMode, error) {
+	switch LayoutMode(s) {
+	case "", LayoutAuto:
+		return LayoutAuto, nil
+	case LayoutSide, LayoutStacked:
+		return LayoutMode(s), nil
+	}
+	return "", fmt.Errorf("invalid layout %q (expected auto, side or stacked)", s)
+}
+
+const (
+	// DefaultStackWidth is the terminal width below which the auto layout
+	// stacks the panes.
+	DefaultStackWidth = 100
+	// minPaneWidth keeps the Files and Content panes usable side by side.
+	minPaneWidth = 20
+	// minPaneRows keeps the Files and Content panes usable when stacked.
+	minPaneRows = 4
+	// paneWidthStep and paneRowsStep are how much one key press resizes.
+	paneWidthStep = 4
+	paneRowsStep  = 2
+)
+
+// PaneLayout is the arrangement of the main views, persisted per directory.
+type PaneLayout struct {
+	Mode        LayoutMode `json:"mode,omitempty"`
+	FilesWidth  int        `json:"filesWidth,omitempty"`  // Side by side, 0 for a third of the screen
+	FilesRows   int        `json:"filesRows,omitempty"`   // Stacked, 0 for half of the space
+	HidePreview bool       `json:"hidePreview,omitempty"` // The Files pane takes the Content view's place
+}
+
+// rect is a view position as passed to gocui's SetView.
+type rect struct {
+	x0, y0, x1, y1 int
+}
+
+// paneRects are the positions of the main views for one terminal size.
+type paneRects struct {
+	path, files, filter, content, status rect
+
+	stacked     bool
+	showContent bool
+	showDivider bool // Side by side with the Content view: the border can be dragged
+	divider     rect
+}
+
+// stacks reports whether layout stacks the panes on a terminal maxX wide.
+func (layout PaneLayout) stacks(maxX, stackWidth int) bool {
+	switch layout.Mode {
+	case LayoutSide:
+		return false
+	case LayoutStacked:
+		return true
+	}
+	return maxX < stackWidth
+}
+
+// panes computes the view positions of layout. The path view (2 lines), the
+// filter (3 lines) and the status bar (2 lines) keep their heights; the
+// Files and Content panes share the rest.
+func (layout PaneLayout) panes(maxX, maxY, stackWidth int) paneRects {
+	const pathHeight, filterHeight, statusBarHeight = 2, 3, 2
+	p := paneRects{showContent: !layout.HidePreview}
+	p.status = rect{0, maxY - statusBarHeight, maxX - 1, maxY}
+	contentY1 := maxY - statusBarHeight
+
+	if p.showContent && layout.stacks(maxX, stackWidth) {
+		p.stacked = true
+		p.path = rect{0, 0, maxX - 1, pathHeight}
+		filesRows := stackedFilesRows(layout.FilesRows, maxY)
+		p.files = rect{0, pathHeight + 1, maxX - 1, pathHeight + filesRows}
+		p.filter = rect{0, p.files.y1 + 1, maxX - 1, p.files.y1 + filterHeight}
+		p.content = rect{0, p.filter.y1 + 1, maxX - 1, contentY1}
+		return p
+	}
+
+	filesWidth := maxX - 1 // The whole width without a preview
+	if p.showContent {
+		filesWidth = sideFilesWidth(layout.FilesWidth, maxX)
+	}
+	p.path = rect{0, 0, filesWidth, pathHeight}
+	filesY1 := maxY - statusBarHeight - filterHeight
+	p.files = rect{0, pathHeight + 1, filesWidth, filesY1}
+	p.filter = rect{0, filesY1 + 1, filesWidth, filesY1 + filterHeight}
+	p.content = rect{filesWidth + 1, 0, maxX - 1, contentY1}
+	if p.showContent {
+		// Invisible, its interior covering the last column and the right
+		// border of the left views, so clicks on the Content view always
+		// reach it: gocui only reports clicks inside views.
+		p.showDivider = true
+		p.divider = rect{filesWidth - 2, -1, filesWidth + 1, maxY}
+	}
+	return p
+}
+
+// sideFilesWidth returns the Files pane width side by side: a third of the
+// screen unless resized, keeping both panes at least minPaneWidth wide where
+// the screen allows it.
+func sideFilesWidth(width, maxX int) int {
+	if width == 0 {
+		return maxX / 3
+	}
+	return max(min(width, maxX-minPaneWidth), min(minPaneWidth, maxX/2))
+}
+
+// stackedFilesRows returns the Files pane height (frame included) when
+// stacked: half of the space left by the fixed views unless resized.
+func stackedFilesRows(rows, maxY int) int {
+	space := maxY - 7 // Path, filter and status bar
+	if rows == 0 {
+		return space / 2
+	}
+	return max(min(rows, space-minPaneRows), min(minPaneRows, space/2))
+}
@@ -0,0 +1,106 @@
+package internal
+
+import "testing"
+
+func TestParseLayoutMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LayoutMode
+		wantErr bool
+	}{
+		{"", LayoutAuto, false},
+		{"auto", LayoutAuto, false},
+		{"side", LayoutSide, false},
+		{"stacked", LayoutStacked, false},
+		{"Side", "", true},
+		{"grid", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLayoutMode(tt.input)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLayoutMode(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSideFilesWidth(t *testing.T) {
+	tests := []struct {
+		name        string
+		width, maxX int
+		want        int
+	}{
+		{"default is a third", 0, 120, 40},
+		{"resized", 50, 120, 50},
+		{"leaves the Content pane its minimum", 110, 120, 120 - minPaneWidth},
+		{"keeps the Files pane its minimum", 5, 120, minPaneWidth},
+		{"narrow screen splits in half at most", 5, 30, 15},
+		{"narrow screen, wide Files pane", 29, 30, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sideFilesWidth(tt.width, tt.maxX); got != tt.want {
+				t.Errorf("sideFilesWidth(%d, %d) = %d, want %d", tt.width, tt.maxX, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackedFilesRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows, maxY int
+		want       int
+	}{
+		{"default is half", 0, 47, 20},
+		{"resized", 12, 47, 12},
+		{"leaves the Content pane its minimum", 40, 47, 40 - minPaneRows},
+		{"keeps the Files pane its minimum", 1, 47, minPaneRows},
+		{"short screen", 1, 11, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stackedFilesRows(tt.rows, tt.maxY); got != tt.want {
+				t.Errorf("stackedFilesRows(%d, %d) = %d, want %d", tt.rows, tt.maxY, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPanes(t *testing.T) {
+	tests := []struct {
+		name        string
+		layout      PaneLayout
+		maxX        int
+		stacked     bool
+		showContent bool
+		files       rect
+		content     rect
+	}{
+		{"auto, wide", PaneLayout{Mode: LayoutAuto}, 120, false, true, rect{0, 3, 40, 25}, rect{41, 0, 119, 28}},
+		{"auto, narrow", PaneLayout{Mode: LayoutAuto}, 80, true, true, rect{0, 3, 79, 13}, rect{0, 17, 79, 28}},
+		{"side, narrow", PaneLayout{Mode: LayoutSide}, 80, false, true, rect{0, 3, 26, 25}, rect{27, 0, 79, 28}},
+		{"stacked, wide", PaneLayout{Mode: LayoutStacked, FilesRows: 6}, 120, true, true, rect{0, 3, 119, 8}, rect{0, 12, 119, 28}},
+		{"hidden preview", PaneLayout{Mode: LayoutStacked, HidePreview: true}, 120, false, false, rect{0, 3, 119, 25}, rect{120, 0, 119, 28}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.layout.panes(tt.maxX, 30, DefaultStackWidth)
+			if p.stacked != tt.stacked || p.showContent != tt.showContent {
+				t.Errorf("stacked %v, showContent %v; want %v, %v", p.stacked, p.showContent, tt.stacked, tt.showContent)
+			}
+			if p.files != tt.files {
+				t.Errorf("files = %v, want %v", p.files, tt.files)
+			}
+			if p.showContent && p.content != tt.content {
+				t.Errorf("content = %v, want %v", p.content, tt.content)
+			}
+			if p.showDivider {
+				// The divider's interior (gocui's click area) is the Files
+				// pane's last column and right border, never the Content view.
+				if p.divider.x0+1 != p.files.x1-1 || p.divider.x1-1 != p.files.x1 {
+					t.Errorf("divider = %v, want its interior at columns %d-%d", p.divider, p.files.x1-1, p.files.x1)
+				}
+			}
+		})
+	}
+}
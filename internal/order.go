@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OrderStrategy decides the bundle order of selected files that were not
+// moved by hand in the Order view.
+type OrderStrategy string
+
+const (
+	OrderAlphabetical OrderStrategy = "alphabetical" // Files view order (default)
+	OrderDependency   OrderStrategy = "dependency"   // Imported Go packages before their importers, other files last
+	OrderMtime        OrderStrategy = "mtime"        // Most recently modified first
+	OrderTestsLast    OrderStrategy = "tests-last"   // Alphabetical, test files last
+)
+
+// orderStrategies is the cycle order of CycleOrderStrategy.
+var orderStrategies = []OrderStrategy{OrderAlphabetical, OrderDependency, OrderMtime, OrderTestsLast}
+
+// ParseOrderStrategy converts a flag or cache value into an OrderStrategy.
+// The empty string is alphabetical.
+func ParseOrderStrategy(value string) (OrderStrategy, error) {
+	switch strategy := OrderStrategy(strings.ToLower(strings.TrimSpace(value))); strategy {
+	case "":
+		return OrderAlphabetical, nil
+	case OrderAlphabetical, OrderDependency, OrderMtime, OrderTestsLast:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid order %q (expected alphabetical, dependency, mtime or tests-last)", value)
+	}
+}
+
+// testDirs are directory names whose files count as tests.
+var testDirs = map[string]bool{"test": true, "tests": true, "__tests__": true, "spec": true, "testdata": true}
+
+// isTestPath reports whether relPath looks like a test file by the naming
+// conventions of common languages.
+func isTestPath(relPath string) bool {
+	for _, dir := range strings.Split(path.Dir(relPath), "/") {
+		if testDirs[dir] {
+			return true
+		}
+	}
+	base := path.Base(relPath)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	return strings.HasSuffix(stem, "_test") || // Go, Python
+		strings.HasPrefix(stem, "test_") || // Python
+		strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec") || // JavaScript/TypeScript
+		strings.HasSuffix(stem, "_spec") || // Ruby
+		(strings.HasSuffix(stem, "Test") && stem != "Test") // Java, Kotlin
+}
+
+// selectionOrder is what the bundle order is decided from, copied under the
+// mutex so that the files can be ordered (which may stat and parse them)
+// without holding it.
+type selectionOrder struct {
+	paths    []string       // Selected files in Files view order
+	pinned   map[string]int // Bundle positions of files moved in the Order view
+	strategy OrderStrategy
+}
+
+// selectionOrder copies the selection and its ordering. Assumes mutex is
+// held.
+func (app *App) selectionOrder() selectionOrder {
+	order := selectionOrder{
+		paths:    make([]string, 0, len(app.selectedFiles)),
+		pinned:   make(map[string]int, len(app.pinnedFiles)),
+		strategy: app.orderStrategy,
+	}
+	for _, relPath := range app.fileList {
+		if app.selectedFiles[relPath] {
+			order.paths = append(order.paths, relPath)
+		}
+	}
+	for relPath, position := range app.pinnedFiles {
+		if app.selectedFiles[relPath] {
+			order.pinned[relPath] = position
+		}
+	}
+	return order
+}
+
+// selectedInOrder returns the selected files in the order they are bundled:
+// files moved in the Order view at the positions they were moved to, the
+// rest around them by the order strategy. Assumes mutex is not held.
+func (app *App) selectedInOrder() []string {
+	app.mutex.Lock()
+	order := app.selectionOrder()
+	app.mutex.Unlock()
+	return app.inBundleOrder(order)
+}
+
+// inBundleOrder orders a selection copied by selectionOrder. Assumes mutex
+// is not held.
+func (app *App) inBundleOrder(order selectionOrder) []string {
+	var rest, pinned []string
+	for _, relPath := range order.paths {
+		if _, ok := order.pinned[relPath]; ok {
+			pinned = append(pinned, relPath)
+		} else {
+			rest = append(rest, relPath)
+		}
+	}
+	rest = app.orderByStrategy(rest, order.strategy)
+	sort.SliceStable(pinned, func(i, j int) bool { return order.pinned[pinned[i]] < order.pinned[pinned[j]] })
+
+	// Pinned files take their positions and the rest fill the gaps. Positions
+	// past the end, or taken by another pinned file, move to the next free
+	// place.
+	ordered := make([]string, 0, len(order.paths))
+	for len(pinned) > 0 || len(rest) > 0 {
+		if len(pinned) > 0 && (order.pinned[pinned[0]] <= len(ordered) || len(rest) == 0) {
+			ordered = append(ordered, pinned[0])
+			pinned = pinned[1:]
+		} else {
+			ordered = append(ordered, rest[0])
+			rest = rest[1:]
+		}
+	}
+	return ordered
+}
+
+// orderByStrategy reorders paths, given in Files view order, by strategy.
+// It only reads the roots, which never change after NewApp, so it needs no
+// lock.
+func (app *App) orderByStrategy(paths []string, strategy OrderStrategy) []string {
+	switch strategy {
+	case OrderDependency:
+		return app.orderByDependency(paths)
+	case OrderMtime:
+		return app.orderByMtime(paths)
+	case OrderTestsLast:
+		var sources, tests []string
+		for _, relPath := range paths {
+			if isTestPath(relPath) {
+				tests = append(tests, relPath)
+			} else {
+				sources = append(sources, relPath)
+			}
+		}
+		return append(sources, tests...)
+	default:
+		return paths
+	}
+}
+
+// orderByMtime puts the most recently modified files first. Files that
+// cannot be stat'ed go last.
+func (app *App) orderByMtime(paths []string) []string {
+	mtimes := make(map[string]time.Time, len(paths))
+	for _, relPath := range paths {
+		if absPath, ok := app.resolvePath(relPath); ok {
+			if info, err := os.Stat(absPath); err == nil {
+				mtimes[relPath] = info.ModTime()
+			}
+		}
+	}
+
+	ordered := append([]string(nil), paths...)
+	sort.SliceStable(ordered, func(i, j int) bool { return mtimes[ordered[i]].After(mtimes[ordered[j]]) })
+	return ordered
+}
+
+// orderByDependency orders Go files so that packages come before the
+// packages importing them, keeping files of one package together in their
+// original order. Other files follow in their original order.
+func (app *App) orderByDependency(paths []string) []string {
+	var dirs []string // Package directories in order of first appearance
+	filesByDir := make(map[string][]string)
+	importsByDir := make(map[string][]string)
+	modules := make(map[string]*goModule)
+	var others []string
+
+	for _, relPath := range paths {
+		absPath, ok := app.resolvePath(relPath)
+		if !ok || !strings.HasSuffix(relPath, ".go") {
+			others = append(others, relPath)
+			continue
+		}
+		dir := filepath.Dir(absPath)
+		if _, seen := filesByDir[dir]; !seen {
+			dirs = append(dirs, dir)
+		}
+		filesByDir[dir] = append(filesByDir[dir], relPath)
+
+		info, err := parseGoHeader(absPath)
+		mod := findGoModule(dir, modules)
+		if err != nil || mod == nil {
+			continue
+		}
+		for _, importPath := range info.imports {
+			if importDir, ok := mod.importDir(importPath); ok && importDir != dir {
+				importsByDir[dir] = append(importsByDir[dir], importDir)
+			}
+		}
+	}
+
+	// Depth-first, emitting a package after the packages it imports. Import
+	// cycles (only possible through external test packages) are cut where
+	// they are found.
+	var ordered []string
+	visited := make(map[string]bool)
+	var visit func(dir string)
+	visit = func(dir string) {
+		if visited[dir] {
+			return
+		}
+		visited[dir] = true
+		for _, importDir := range importsByDir[dir] {
+			if _, selected := filesByDir[importDir]; selected {
+				visit(importDir)
+			}
+		}
+		ordered = append(ordered, filesByDir[dir]...)
+	}
+	for _, dir := range dirs {
+		visit(dir)
+	}
+	return append(ordered, others...)
+}
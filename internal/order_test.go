@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInBundleOrder(t *testing.T) {
+	paths := []string{"a.go", "a_test.go", "b.go", "c.go"}
+	tests := []struct {
+		name     string
+		pinned   map[string]int
+		strategy OrderStrategy
+		want     string
+	}{
+		{"strategy only", nil, OrderTestsLast, "a.go b.go c.go a_test.go"},
+		{"pinned first", map[string]int{"c.go": 0}, OrderTestsLast, "c.go a.go b.go a_test.go"},
+		{"pinned inside", map[string]int{"a_test.go": 1}, OrderTestsLast, "a.go a_test.go b.go c.go"},
+		{"past the end", map[string]int{"a.go": 9}, OrderAlphabetical, "a_test.go b.go c.go a.go"},
+		{"same position", map[string]int{"c.go": 0, "b.go": 0}, OrderAlphabetical, "b.go c.go a.go a_test.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, nil)
+			got := app.inBundleOrder(selectionOrder{paths: paths, pinned: tt.pinned, strategy: tt.strategy})
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("order = %s, want %s", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+// TestMovePinned moves files as the Order view does and checks that only the
+// moved files are pinned while the others keep following the strategy.
+func TestMovePinned(t *testing.T) {
+	app := newTestApp(t, nil)
+	paths := []string{"a.go", "b.go", "c.go", "d.go"}
+	var pinned map[string]int
+	move := func(from, to int) []string {
+		order := app.inBundleOrder(selectionOrder{paths: paths, pinned: pinned, strategy: OrderAlphabetical})
+		pinned = movePinned(order, pinned, from, to)
+		return app.inBundleOrder(selectionOrder{paths: paths, pinned: pinned, strategy: OrderAlphabetical})
+	}
+
+	steps := []struct {
+		from, to int
+		order    string
+		pinned   string
+	}{
+		{2, 1, "a.go c.go b.go d.go", "c.go"},
+		{1, 0, "c.go a.go b.go d.go", "c.go"},
+		{3, 2, "c.go a.go d.go b.go", "c.go d.go"},
+		{2, 0, "d.go a.go c.go b.go", "c.go d.go"}, // Swaps the two pinned files
+	}
+	for i, step := range steps {
+		order := move(step.from, step.to)
+		if strings.Join(order, " ") != step.order {
+			t.Errorf("step %d: order = %s, want %s", i+1, strings.Join(order, " "), step.order)
+		}
+		if got := strings.Join(slices.Sorted(maps.Keys(pinned)), " "); got != step.pinned {
+			t.Errorf("step %d: pinned = %s, want %s", i+1, got, step.pinned)
+		}
+	}
+}
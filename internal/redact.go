@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// secretPattern is a kind of secret redacted from copied bundles. The secret
+// is the whole match, or the submatch at group when group > 0.
+type secretPattern struct {
+	kind  string
+	re    *regexp.Regexp
+	group int
+}
+
+var secretPatterns = []secretPattern{
+	{kind: "private-key", re: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)},
+	{kind: "aws-access-key", re: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	// password = "..." / token: '...' and friends, quoted values.
+	{kind: "secret", group: 1, re: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret)["']?\s*(?::=|=|:)\s*["']([^"'\s]{4,})["']`)},
+	// PASSWORD=hunter2 as in .env files: unquoted values at the start of a line.
+	{kind: "secret", group: 1, re: regexp.MustCompile(`(?m)^\s*(?:export\s+)?[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY)[A-Z0-9_]*\s*[=:]\s*([^"'\s#]{4,})`)},
+}
+
+// highEntropyCandidate matches long runs of base64/hex-like characters.
+var highEntropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+
+// checksumFiles hold hashes by design; high-entropy detection skips them.
+var checksumFiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"composer.lock":     true,
+	"Gemfile.lock":      true,
+}
+
+// redactionPlaceholder replaces a secret of the given kind.
+func redactionPlaceholder(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// redactSecrets replaces likely secrets in content with placeholders and
+// returns the result and the number of secrets replaced.
+func redactSecrets(relPath, content string) (string, int) {
+	return redact(relPath, content, false)
+}
+
+// redactLines redacts lines as redactSecrets redacts their text, before line
+// numbers or truncation markers are added. A secret spanning several lines is
+// replaced on its first line and its other lines are dropped, so the lines
+// kept keep their numbers.
+func redactLines(relPath string, lines []numberedLine) ([]numberedLine, int) {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	redacted, count := redact(relPath, strings.Join(texts, "\n"), true)
+	if count == 0 {
+		return lines, 0
+	}
+
+	kept := make([]numberedLine, 0, len(lines))
+	for i, text := range strings.Split(redacted, "\n") {
+		if text == "" && lines[i].text != "" {
+			continue // Inside a multi-line secret
+		}
+		kept = append(kept, numberedLine{no: lines[i].no, text: text})
+	}
+	return kept, count
+}
+
+// redact implements redactSecrets. With keepLines, a placeholder is followed
+// by the newlines of the secret it replaces, so content keeps its line count.
+func redact(relPath, content string, keepLines bool) (string, int) {
+	count := 0
+	for _, p := range secretPatterns {
+		content = replaceSecret(content, p, keepLines, &count)
+	}
+	if !checksumFiles[path.Base(relPath)] {
+		content = highEntropyCandidate.ReplaceAllStringFunc(content, func(s string) string {
+			if !looksRandom(s) {
+				return s
+			}
+			count++
+			return redactionPlaceholder("high-entropy")
+		})
+	}
+	return content, count
+}
+
+// replaceSecret replaces every match of p in content, counting replacements.
+func replaceSecret(content string, p secretPattern, keepLines bool, count *int) string {
+	matches := p.re.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if p.group > 0 {
+			start, end = m[2*p.group], m[2*p.group+1]
+		}
+		if start < last || strings.HasPrefix(content[start:end], "[REDACTED:") {
+			continue // Already redacted by an earlier pattern
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(redactionPlaceholder(p.kind))
+		if keepLines {
+			b.WriteString(strings.Repeat("\n", strings.Count(content[start:end], "\n")))
+		}
+		last = end
+		*count++
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// looksRandom reports whether s looks like a key or token rather than a word,
+// path or identifier: it mixes upper and lower case letters with digits and
+// has high entropy.
+func looksRandom(s string) bool {
+	var upper, lower, digits bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digits = true
+		}
+	}
+	if !digits || !upper || !lower {
+		return false // Words, identifiers and hex digests such as commit hashes
+	}
+	return shannonEntropy(s) >= 4.3
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]int)
+	for _, r := range s {
+		freq[r]++
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, c := range freq {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// isSensitivePath reports whether relPath is a file that typically holds
+// secrets (.env files, PEM keys) and must be selected explicitly.
+func isSensitivePath(relPath string) bool {
+	base := path.Base(relPath)
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".pem")
+}
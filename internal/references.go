@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// referenceHit is a file that mentions the identifier being searched for.
+type referenceHit struct {
+	path      string
+	count     int  // Number of references in the file
+	declares  bool // The file declares the identifier (Go only)
+	hidden    bool // Not in the Files view because of the current filter
+	nameMatch bool // Counted by name, not resolved (see findReferences)
+}
+
+// declarationPattern picks the declared name out of a line such as
+// "func (a *App) Name(", "type Name struct", "def name(" or "fn name(".
+var declarationPattern = regexp.MustCompile(`\b(?:func(?:\s*\([^)]*\))?|type|var|const|def|class|function|fn|struct|enum|trait|interface)\s+([A-Za-z_]\w*)`)
+
+var (
+	identifierPattern      = regexp.MustCompile(`[A-Za-z_]\w*`)
+	validIdentifierPattern = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+)
+
+// pickIdentifier returns the identifier a line is most likely about: the name
+// it declares, otherwise its first identifier that is not a keyword.
+func pickIdentifier(line string) string {
+	if m := declarationPattern.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	for _, word := range identifierPattern.FindAllString(line, -1) {
+		if !token.Lookup(word).IsKeyword() {
+			return word
+		}
+	}
+	return ""
+}
+
+// normalizeIdentifier trims a typed identifier and splits a qualified name
+// such as pkg.Name into the package name and the identifier. ident is empty
+// if the input is not an identifier.
+func normalizeIdentifier(input string) (qualifier, ident string) {
+	ident = strings.TrimSpace(input)
+	if i := strings.LastIndex(ident, "."); i >= 0 {
+		qualifier, ident = ident[:i], ident[i+1:]
+		if !validIdentifierPattern.MatchString(qualifier) {
+			return "", ""
+		}
+	}
+	if !validIdentifierPattern.MatchString(ident) {
+		return "", ""
+	}
+	return qualifier, ident
+}
+
+// goSourceFile is a parsed Go file and the package it belongs to, which is
+// identified by its directory.
+type goSourceFile struct {
+	path       string // Display path
+	dir        string // Display path of the package directory
+	relDir     string // Package directory relative to its root
+	importPath string // The package's import path, "" without a go.mod in the root
+	file       *ast.File
+}
+
+// importedAs reports whether importPath names the package of f. Without a
+// module path the package directory has to end the import path.
+func (f goSourceFile) importedAs(importPath string) bool {
+	if f.importPath != "" {
+		return importPath == f.importPath
+	}
+	return f.relDir != "." && (importPath == f.relDir || strings.HasSuffix(importPath, "/"+f.relDir))
+}
+
+// goModulePath returns the module path declared in the go.mod of dir, or ""
+// if there is none.
+func goModulePath(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok && rest != "" {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// importName returns the name spec is referred to by in its file: the alias,
+// or defaultName (the package name, or the import path's last element).
+func importName(spec *ast.ImportSpec, defaultName string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return defaultName
+}
+
+// declaresPackageLevel reports whether file declares ident at package level:
+// a function, type, variable or constant, but not a method or a field.
+func declaresPackageLevel(file *ast.File, ident string) bool {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name == ident {
+				return true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.Name == ident {
+						return true
+					}
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if name.Name == ident {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// countPackageReferences counts the references in file to the package-level
+// ident: plain identifiers when the file is in (or dot-imports) the declaring
+// package, and selectors such as pkg.ident for the import names in imported.
+// Local declarations shadowing ident, fields, methods and struct literal keys
+// of the same name are not references.
+func countPackageReferences(file *ast.File, ident string, inPackage bool, imported map[string]bool) int {
+	notReferences := make(map[*ast.Ident]bool)
+	count := 0
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := node.X.(*ast.Ident); ok && x.Obj == nil && imported[x.Name] && node.Sel.Name == ident {
+				count++
+			}
+			notReferences[node.Sel] = true // A field, method or another package's name
+		case *ast.FuncDecl:
+			if node.Recv != nil {
+				notReferences[node.Name] = true
+			}
+		case *ast.Field:
+			for _, name := range node.Names {
+				notReferences[name] = true
+			}
+		case *ast.CompositeLit:
+			for _, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.Ident); ok {
+						notReferences[key] = true
+					}
+				}
+			}
+		case *ast.Ident:
+			// Package-level names of this file resolve to its scope; those
+			// of other files in the package are left unresolved.
+			if inPackage && node.Name == ident && !notReferences[node] &&
+				(node.Obj == nil || node.Obj == file.Scope.Lookup(ident)) {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// countGoNames counts the identifiers named ident in a Go file, whatever they
+// refer to, and reports whether the file declares something of that name.
+func countGoNames(file *ast.File, ident string) (count int, declares bool) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			declares = declares || node.Name.Name == ident
+		case *ast.TypeSpec:
+			declares = declares || node.Name.Name == ident
+		case *ast.ValueSpec:
+			for _, name := range node.Names {
+				declares = declares || name.Name == ident
+			}
+		case *ast.Ident:
+			if node.Name == ident {
+				count++
+			}
+		}
+		return true
+	})
+	return count, declares
+}
+
+// findReferences searches every discovered file for ident, qualified by a
+// package name if qualifier is set. In Go files a package-level ident is
+// resolved: it counts in its own package, and as a selector in the files
+// importing it. Names with no package-level declaration (methods, fields,
+// other modules' packages) are matched by name, unless qualified, and files
+// that are not Go or fail to parse by a word-boundary match; those hits are
+// marked nameMatch. Files that fail to read are left out.
+func (app *App) findReferences(qualifier, ident string) []referenceHit {
+	app.mutex.Lock()
+	allFiles := append([]string(nil), app.allFiles...)
+	visible := make(map[string]bool, len(app.fileList))
+	for _, relPath := range app.fileList {
+		visible[relPath] = true
+	}
+	goRoots := make(map[string]Root) // Go file -> its root
+	goRelDirs := make(map[string]string)
+	for _, relPath := range allFiles {
+		if root, rel, ok := app.splitDisplayPath(relPath); ok && strings.HasSuffix(relPath, ".go") {
+			goRoots[relPath], goRelDirs[relPath] = root, path.Dir(rel)
+		}
+	}
+	app.mutex.Unlock()
+
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(ident) + `\b`)
+	modules := make(map[string]string) // Root directory -> module path
+
+	var hits []referenceHit
+	var goFiles []goSourceFile
+	for _, relPath := range allFiles {
+		content, err := app.readFileContent(relPath)
+		if err != nil {
+			continue
+		}
+		if root, ok := goRoots[relPath]; ok {
+			if file, err := parser.ParseFile(token.NewFileSet(), relPath, content, 0); err == nil {
+				module, known := modules[root.Dir]
+				if !known {
+					module = goModulePath(root.Dir)
+					modules[root.Dir] = module
+				}
+				f := goSourceFile{path: relPath, dir: path.Dir(relPath), relDir: goRelDirs[relPath], file: file}
+				if module != "" {
+					f.importPath = path.Join(module, f.relDir)
+				}
+				goFiles = append(goFiles, f)
+				continue
+			}
+		}
+		hit := referenceHit{path: relPath, hidden: !visible[relPath], nameMatch: true}
+		hit.count = len(word.FindAllIndex(content, -1))
+		if hit.count > 0 {
+			hits = append(hits, hit)
+		}
+	}
+
+	declaring := make(map[string]goSourceFile) // Package directory -> a file of it
+	for _, f := range goFiles {
+		if (qualifier == "" || f.file.Name.Name == qualifier) && declaresPackageLevel(f.file, ident) {
+			declaring[f.dir] = f
+		}
+	}
+
+	for _, f := range goFiles {
+		hit := referenceHit{path: f.path, hidden: !visible[f.path]}
+		imported := make(map[string]bool)
+		switch {
+		case len(declaring) > 0:
+			_, inPackage := declaring[f.dir]
+			hit.declares = inPackage && declaresPackageLevel(f.file, ident)
+			for _, spec := range f.file.Imports {
+				importPath, _ := strconv.Unquote(spec.Path.Value)
+				for _, pkg := range declaring {
+					if !pkg.importedAs(importPath) {
+						continue
+					}
+					if name := importName(spec, pkg.file.Name.Name); name == "." {
+						inPackage = true
+					} else {
+						imported[name] = true
+					}
+				}
+			}
+			hit.count = countPackageReferences(f.file, ident, inPackage, imported)
+		case qualifier != "":
+			// A package outside the roots, such as fmt in fmt.Println.
+			for _, spec := range f.file.Imports {
+				importPath, _ := strconv.Unquote(spec.Path.Value)
+				if importName(spec, path.Base(importPath)) == qualifier {
+					imported[qualifier] = true
+				}
+			}
+			hit.count = countPackageReferences(f.file, ident, false, imported)
+		default:
+			hit.count, hit.declares = countGoNames(f.file, ident)
+			hit.nameMatch = true
+		}
+		if hit.count > 0 {
+			hits = append(hits, hit)
+		}
+	}
+
+	order := make(map[string]int, len(allFiles))
+	for i, relPath := range allFiles {
+		order[relPath] = i
+	}
+	sort.Slice(hits, func(i, j int) bool { return order[hits[i].path] < order[hits[j].path] })
+	return hits
+}
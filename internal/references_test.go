@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeIdentifier(t *testing.T) {
+	tests := []struct {
+		input, qualifier, ident string
+	}{
+		{" Name ", "", "Name"},
+		{"pkg.Name", "pkg", "Name"},
+		{"a.b.Name", "", ""},
+		{"Name(", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		qualifier, ident := normalizeIdentifier(tt.input)
+		if qualifier != tt.qualifier || ident != tt.ident {
+			t.Errorf("normalizeIdentifier(%q) = %q, %q; want %q, %q", tt.input, qualifier, ident, tt.qualifier, tt.ident)
+		}
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"store/store.go": `package store
+
+// Open opens the store.
+func Open() *Store { return &Store{} }
+
+type Store struct{ Open bool }
+
+func (s *Store) Close() { s.Open = false }
+`,
+		"store/util.go": "package store\n\nfunc reopen() { Open() }\n",
+		"main.go": `package main
+
+import (
+	db "example.com/app/store"
+	"os"
+)
+
+func main() {
+	s := db.Open()
+	s.Close()
+	os.Open("x")
+	Open := 1
+	_ = Open
+}
+`,
+		"cli/cli.go": `package cli
+
+import "os"
+
+func Open() { os.Open("y") }
+`,
+		"README.md": "Call Open() first.\n",
+	}
+
+	tests := []struct {
+		query string
+		want  string // path:count, with * for files that declare it and ~ for name matches
+	}{
+		// The package-level Open of store: not cli's, the field, os.Open or
+		// main's local variable. There are two declaring packages, though.
+		{"store.Open", "README.md:1~ main.go:1 store/store.go:1* store/util.go:1"},
+		{"Open", "README.md:1~ cli/cli.go:1* main.go:1 store/store.go:1* store/util.go:1"},
+		{"os.Open", "README.md:1~ cli/cli.go:1 main.go:1"},
+		// A method has no package-level declaration: matched by name.
+		{"Close", "main.go:1~ store/store.go:1*~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			app := newTestApp(t, files)
+			for relPath := range files {
+				app.allFiles = append(app.allFiles, relPath)
+			}
+			app.fileList = app.allFiles
+
+			var got []string
+			for _, hit := range app.findReferences(normalizeIdentifier(tt.query)) {
+				entry := fmt.Sprintf("%s:%d", hit.path, hit.count)
+				if hit.declares {
+					entry += "*"
+				}
+				if hit.nameMatch {
+					entry += "~"
+				}
+				got = append(got, entry)
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("findReferences(%s) = %s, want %s", tt.query, strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// SelectionReport describes the outcome of pre-selecting paths from a list.
+type SelectionReport struct {
+	Source    string   // "stdin" or the list file name
+	Requested int      // Unique paths read from the list
+	Selected  int      // Paths that were found and selected
+	Missing   []string // Paths not found under any root
+	Rejected  []string // Paths found but not selectable, with the reason
+}
+
+// Summary returns a one-line description suitable for the status bar.
+func (r SelectionReport) Summary() string {
+	summary := fmt.Sprintf("Pre-selected %d of %d path(s) from %s", r.Selected, r.Requested, r.Source)
+	if len(r.Missing) > 0 {
+		summary += fmt.Sprintf("; not found: %s", strings.Join(r.Missing, ", "))
+	}
+	if len(r.Rejected) > 0 {
+		summary += fmt.Sprintf("; filtered out: %s", strings.Join(r.Rejected, ", "))
+	}
+	return summary
+}
+
+// ReadPathList reads newline-separated paths from source, where "-" means stdin.
+// Blank lines are ignored.
+func ReadPathList(source string) ([]string, error) {
+	var r io.Reader
+	if source == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open path list %s: %w", source, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read path list %s: %w", source, err)
+	}
+	return paths, nil
+}
+
+// SelectPaths selects the given paths after ListFiles has run. Paths may be
+// absolute, display paths (relative to the root, or "label/relpath" with
+// several roots) or relative to the working directory, as printed by tools
+// like `rg -l` or `git diff --name-only`.
+func (app *App) SelectPaths(paths []string, source string) SelectionReport {
+	app.mutex.Lock()
+
+	report := SelectionReport{Source: source}
+	if source == "-" {
+		report.Source = "stdin"
+	}
+
+	visible := make(map[string]bool, len(app.fileList))
+	for _, file := range app.fileList {
+		visible[file] = true
+	}
+	discovered := make(map[string]bool, len(app.allFiles))
+	for _, file := range app.allFiles {
+		discovered[file] = true
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		displayPath, ok := app.toDisplayPath(path, discovered)
+		if seen[path] || (ok && seen[displayPath]) {
+			continue
+		}
+		seen[path] = true
+		report.Requested++
+		if !ok {
+			report.Missing = append(report.Missing, path)
+			continue
+		}
+		seen[displayPath] = true
+
+		switch {
+		case visible[displayPath]:
+			app.selectedFiles[displayPath] = true
+			report.Selected++
+		case discovered[displayPath]:
+			report.Rejected = append(report.Rejected, fmt.Sprintf("%s (%s)", path, SkipFilter))
+		default:
+			if reason, skipped := app.skipReasonFor(displayPath); skipped {
+				report.Rejected = append(report.Rejected, fmt.Sprintf("%s (%s)", path, reason))
+			} else {
+				report.Missing = append(report.Missing, path)
+			}
+		}
+	}
+	app.mutex.Unlock()
+
+	if app.g != nil {
+		app.g.Update(func(g *gocui.Gui) error {
+			app.refreshFilesView(g)
+			return nil
+		})
+	}
+	return report
+}
+
+// toDisplayPath maps a path from a list to a display path. Assumes mutex is held.
+func (app *App) toDisplayPath(path string, discovered map[string]bool) (string, bool) {
+	slashPath := strings.TrimPrefix(filepath.ToSlash(path), "./")
+	if !filepath.IsAbs(path) {
+		// Already a display path?
+		if _, _, known := app.splitDisplayPath(slashPath); known && discovered[slashPath] {
+			return slashPath, true
+		}
+		if _, skipped := app.skipReasonFor(slashPath); skipped {
+			return slashPath, true
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return "", false
+		}
+		path = absPath
+	}
+
+	for _, root := range app.roots {
+		if !isWithinDir(root.Dir, path) {
+			continue
+		}
+		relPath, err := filepath.Rel(root.Dir, path)
+		if err != nil || relPath == "." {
+			continue
+		}
+		return app.displayPrefix(root) + filepath.ToSlash(relPath), true
+	}
+
+	// Not under any root via the working directory: fall back to treating it
+	// as relative to the (single) root, e.g. `git diff --name-only` output.
+	if _, _, known := app.splitDisplayPath(slashPath); known && !filepath.IsAbs(slashPath) {
+		return slashPath, true
+	}
+	return "", false
+}
+
+// skipReasonFor returns why displayPath (or a directory containing it) was
+// skipped during discovery. Assumes mutex is held.
+func (app *App) skipReasonFor(displayPath string) (SkipReason, bool) {
+	if entry, ok := app.skippedFiles[displayPath]; ok {
+		return entry.Reason, true
+	}
+	for path, entry := range app.skippedFiles {
+		if strings.HasSuffix(path, "/") && strings.HasPrefix(displayPath, path) {
+			return entry.Reason, true
+		}
+	}
+	return "", false
+}
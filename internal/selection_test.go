@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadPathList(t *testing.T) {
+	listFile := filepath.Join(t.TempDir(), "paths.txt")
+	if err := os.WriteFile(listFile, []byte("  a.go \n\n\tsub/b.go\n   \nc.go"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := ReadPathList(listFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.go", "sub/b.go", "c.go"}; !slices.Equal(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+	if _, err := ReadPathList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("reading a missing list succeeded")
+	}
+}
+
+// TestSelectPaths pre-selects paths of every accepted form from a single root
+// and checks which are selected and how the others are reported.
+func TestSelectPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":   "build/\n*.log\n",
+		"main.go":      "package main\n",
+		"lib/a.go":     "package lib\n",
+		"lib/b.go":     "package lib\n",
+		"README.md":    "# readme\n",
+		"debug.log":    "log\n",
+		"build/out.go": "package build\n",
+	})
+	app := newWalkApp(t, dir)
+	app.excludes = DefaultExcludes + ",*.md"
+	listFiles(t, app)
+
+	report := app.SelectPaths([]string{
+		filepath.Join(dir, "main.go"), // Absolute
+		"lib/a.go",                    // Root-relative
+		"./lib/b.go",                  // With a ./ prefix
+		"lib/a.go",                    // Repeated
+		"README.md",                   // Removed by the filter
+		"debug.log",                   // Gitignored
+		"build/out.go",                // In a gitignored directory
+		"missing.go",
+		filepath.Join(t.TempDir(), "elsewhere.go"),
+	}, "-")
+
+	if want := []string{"lib/a.go", "lib/b.go", "main.go"}; !slices.Equal(slices.Sorted(maps.Keys(app.selectedFiles)), want) {
+		t.Errorf("selected = %v, want %v", slices.Sorted(maps.Keys(app.selectedFiles)), want)
+	}
+	if report.Source != "stdin" || report.Requested != 8 || report.Selected != 3 {
+		t.Errorf("report = %+v, want 3 of 8 selected from stdin", report)
+	}
+	wantRejected := []string{"README.md (filter)", "debug.log (gitignore)", "build/out.go (gitignore)"}
+	if !slices.Equal(report.Rejected, wantRejected) {
+		t.Errorf("rejected = %q, want %q", report.Rejected, wantRejected)
+	}
+	if len(report.Missing) != 2 || report.Missing[0] != "missing.go" {
+		t.Errorf("missing = %q, want missing.go and elsewhere.go", report.Missing)
+	}
+	if summary := report.Summary(); !strings.Contains(summary, "not found: missing.go") || !strings.Contains(summary, "filtered out: README.md (filter)") {
+		t.Errorf("summary = %q", summary)
+	}
+}
+
+// TestSelectPathsMultiRoot checks that label-prefixed and absolute paths map
+// to the files of the right root when two roots share a directory name.
+func TestSelectPathsMultiRoot(t *testing.T) {
+	base := t.TempDir()
+	first, second := filepath.Join(base, "one", "app"), filepath.Join(base, "two", "app")
+	writeTree(t, first, map[string]string{"main.go": "package main\n", "lib/a.go": "package lib\n"})
+	writeTree(t, second, map[string]string{"main.go": "package main\n"})
+	app := newWalkApp(t, first, second)
+	listFiles(t, app)
+
+	report := app.SelectPaths([]string{
+		"app/lib/a.go",
+		filepath.Join(second, "main.go"),
+		"main.go",       // Ambiguous without a label
+		"app-3/main.go", // Unknown label
+	}, "paths.txt")
+
+	if want := []string{"app-2/main.go", "app/lib/a.go"}; !slices.Equal(slices.Sorted(maps.Keys(app.selectedFiles)), want) {
+		t.Errorf("selected = %v, want %v", slices.Sorted(maps.Keys(app.selectedFiles)), want)
+	}
+	if report.Source != "paths.txt" || report.Selected != 2 || !slices.Equal(report.Missing, []string{"main.go", "app-3/main.go"}) {
+		t.Errorf("report = %+v, want main.go and app-3/main.go missing", report)
+	}
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// RenderMode selects how a file's content is bundled.
+type RenderMode int
+
+const (
+	RenderFull     RenderMode = iota // The file as it is (default)
+	RenderSkeleton                   // Go API shape only: declarations and signatures, bodies elided
+)
+
+// supportsSkeleton reports whether relPath can be rendered as a skeleton.
+func supportsSkeleton(relPath string) bool {
+	return strings.EqualFold(filepath.Ext(relPath), ".go")
+}
+
+// goSkeleton renders Go source as its API shape: the package clause, imports,
+// type, const and var declarations, and function and method signatures with
+// their doc comments. Function bodies are replaced by { ... }.
+func goSkeleton(filename string, src []byte) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+
+	// Same settings as gofmt.
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+	var b bytes.Buffer
+	writeDoc(&b, file.Doc)
+	b.WriteString("package " + file.Name.Name + "\n")
+
+	for _, decl := range file.Decls {
+		b.WriteString("\n")
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			writeDoc(&b, d.Doc)
+			hasBody := d.Body != nil
+			d.Doc, d.Body = nil, nil
+			if err := cfg.Fprint(&b, fset, d); err != nil {
+				return "", err
+			}
+			if hasBody {
+				b.WriteString(" { ... }")
+			}
+			b.WriteString("\n")
+		case *ast.GenDecl:
+			// Keep the doc comment and comments inside the declaration, such
+			// as field comments.
+			start := d.Pos()
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			node := &printer.CommentedNode{Node: d, Comments: commentsWithin(file.Comments, start, d.End())}
+			if err := cfg.Fprint(&b, fset, node); err != nil {
+				return "", err
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// writeDoc writes a doc comment group as it appears in the source.
+func writeDoc(b *bytes.Buffer, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	for _, c := range doc.List {
+		b.WriteString(c.Text + "\n")
+	}
+}
+
+// commentsWithin returns the comment groups lying inside [pos, end].
+func commentsWithin(groups []*ast.CommentGroup, pos, end token.Pos) []*ast.CommentGroup {
+	var within []*ast.CommentGroup
+	for _, group := range groups {
+		if group.Pos() >= pos && group.End() <= end {
+			within = append(within, group)
+		}
+	}
+	return within
+}
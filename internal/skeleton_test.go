@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoSkeleton(t *testing.T) {
+	source := `// Package shapes draws shapes.
+package shapes
+
+import (
+	"fmt"
+	"math"
+)
+
+// Unit is the default scale.
+const (
+	Unit  = 1.0 // One unit
+	Scale = 2   // Doubles
+)
+
+var (
+	// Origin is the centre.
+	Origin = Point{}
+	count  int // Shapes drawn
+)
+
+// Point is a position.
+type Point struct {
+	X, Y float64 // Coordinates
+	// Label names the point.
+	Label string
+}
+
+// Dist returns the distance to q.
+func (p Point) Dist(q Point) float64 {
+	// Not in the skeleton.
+	return math.Hypot(q.X-p.X, q.Y-p.Y)
+}
+
+func (p *Point) String() string { return fmt.Sprint(p.X, p.Y) }
+
+// sqrt is implemented in assembly.
+func sqrt(x float64) float64
+
+func draw[T any](shapes ...T) {
+	count++
+}
+`
+	want := `// Package shapes draws shapes.
+package shapes
+
+import (
+	"fmt"
+	"math"
+)
+
+// Unit is the default scale.
+const (
+	Unit  = 1.0 // One unit
+	Scale = 2   // Doubles
+)
+
+var (
+	// Origin is the centre.
+	Origin = Point{}
+	count  int // Shapes drawn
+)
+
+// Point is a position.
+type Point struct {
+	X, Y float64 // Coordinates
+	// Label names the point.
+	Label string
+}
+
+// Dist returns the distance to q.
+func (p Point) Dist(q Point) float64 { ... }
+
+func (p *Point) String() string { ... }
+
+// sqrt is implemented in assembly.
+func sqrt(x float64) float64
+
+func draw[T any](shapes ...T) { ... }
+`
+	got, err := goSkeleton("shapes.go", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("goSkeleton =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestSkeletonSectionFallback checks that a file that does not parse is
+// bundled in full, with the parse error in the header.
+func TestSkeletonSectionFallback(t *testing.T) {
+	source := "package broken\n\nfunc f( {\n"
+	app := newTestApp(t, map[string]string{"broken.go": source})
+	app.renderModes["broken.go"] = RenderSkeleton
+
+	sections, err := app.fileSections("broken.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 1 {
+		t.Fatalf("sections = %+v, want one", sections)
+	}
+	if header := sections[0].header; !strings.HasPrefix(header, "broken.go (full: skeleton failed: broken.go:3:") {
+		t.Errorf("header = %q, want the parse error", header)
+	}
+	if sections[0].content != source {
+		t.Errorf("content = %q, want the full file", sections[0].content)
+	}
+}
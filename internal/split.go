@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitLimit caps the size of each part when CopyAllSelected splits an
+// oversized bundle. A zero field means no limit of that kind.
+type SplitLimit struct {
+	Tokens int
+	Bytes  int
+}
+
+// Enabled reports whether a limit is set.
+func (l SplitLimit) Enabled() bool {
+	return l.Tokens > 0 || l.Bytes > 0
+}
+
+// bundlePart is one labelled part of a split bundle.
+type bundlePart struct {
+	text     string
+	paths    []string // Files wholly or partly in the part, in bundle order
+	redacted []string // Those of paths in which secrets were redacted
+}
+
+// splitBlock is a unit the splitter keeps together if it can: one file's
+// bundle entry, or the instruction (path "").
+type splitBlock struct {
+	path string
+	text string
+}
+
+// partSize is the size of text in both limit units.
+type partSize struct {
+	tokens, bytes int
+}
+
+func (s partSize) add(o partSize) partSize {
+	return partSize{tokens: s.tokens + o.tokens, bytes: s.bytes + o.bytes}
+}
+
+// within reports whether s respects every limit set in l.
+func (s partSize) within(l SplitLimit) bool {
+	return (l.Tokens == 0 || s.tokens <= l.Tokens) && (l.Bytes == 0 || s.bytes <= l.Bytes)
+}
+
+// sizer returns a function sizing text. Without a tokenizer, tokens are
+// estimated at four bytes each so a token limit still applies. Assumes mutex
+// is not held.
+func (app *App) sizer() func(text string) partSize {
+	app.mutex.Lock()
+	tokenizer := app.tokenizer
+	app.mutex.Unlock()
+
+	return func(text string) partSize {
+		size := partSize{bytes: len(text), tokens: (len(text) + 3) / 4}
+		if tokenizer != nil {
+			size.tokens = len(tokenizer.Encode(text, nil, nil))
+		}
+		return size
+	}
+}
+
+// partHeader and partFooter label part i (1-based) of n.
+func partHeader(i, n int) string {
+	return fmt.Sprintf("Part %d/%d\n\n", i, n)
+}
+
+func partFooter(i, n int) string {
+	return fmt.Sprintf("Part %d/%d ends here. Wait for all %d parts before answering.\n", i, n, n)
+}
+
+// splitBundle splits the bundle, with the instruction, into parts within the
+// split limit, cutting at file boundaries and inside a file only when the file
+// alone exceeds the limit. Sizes are summed per file, so token counts are
+// approximate. It returns nil if splitting is off or the bundle fits as is.
+func (app *App) splitBundle(result bundle) []bundlePart {
+	app.mutex.Lock()
+	limit := app.splitLimit
+	instruction := app.instruction
+	after := app.instructionAfter
+	app.mutex.Unlock()
+
+	if !limit.Enabled() {
+		return nil
+	}
+	measure := app.sizer()
+
+	var blocks []splitBlock
+	if instruction != "" && !after {
+		blocks = append(blocks, splitBlock{text: instruction + "\n\n"})
+	}
+	for _, entry := range result.entries {
+		blocks = append(blocks, splitBlock{path: entry.path, text: entry.block})
+	}
+	if instruction != "" && after {
+		blocks = append(blocks, splitBlock{text: instruction + "\n"})
+	}
+
+	sizes := make([]partSize, len(blocks))
+	var total partSize
+	for i, b := range blocks {
+		sizes[i] = measure(b.text)
+		total = total.add(sizes[i])
+	}
+	if total.within(limit) {
+		return nil
+	}
+
+	// Leave room for the part labels, measured with generous part numbers.
+	overhead := measure(partHeader(99, 99) + partFooter(99, 99))
+	budget := limit
+	if budget.Tokens > 0 {
+		budget.Tokens = max(budget.Tokens-overhead.tokens, 1)
+	}
+	if budget.Bytes > 0 {
+		budget.Bytes = max(budget.Bytes-overhead.bytes, 1)
+	}
+
+	// Pack blocks greedily, splitting the ones too large for any part.
+	var groups [][]splitBlock
+	var current []splitBlock
+	var currentSize partSize
+	pack := func(b splitBlock, size partSize) {
+		if len(current) > 0 && !currentSize.add(size).within(budget) {
+			groups = append(groups, current)
+			current, currentSize = nil, partSize{}
+		}
+		current = append(current, b)
+		currentSize = currentSize.add(size)
+	}
+	for i, b := range blocks {
+		if sizes[i].within(budget) {
+			pack(b, sizes[i])
+			continue
+		}
+		pieces, pieceSizes := splitOversized(b, sizes[i], budget, measure)
+		for j, piece := range pieces {
+			pack(piece, pieceSizes[j])
+		}
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	redacted := make(map[string]bool, len(result.redacted))
+	for _, relPath := range result.redacted {
+		redacted[relPath] = true
+	}
+
+	parts := make([]bundlePart, len(groups))
+	for i, group := range groups {
+		var b strings.Builder
+		b.WriteString(partHeader(i+1, len(groups)))
+		seen := make(map[string]bool)
+		for _, block := range group {
+			b.WriteString(block.text)
+			if block.path != "" && !seen[block.path] {
+				seen[block.path] = true
+				parts[i].paths = append(parts[i].paths, block.path)
+				if redacted[block.path] {
+					parts[i].redacted = append(parts[i].redacted, block.path)
+				}
+			}
+		}
+		if i < len(groups)-1 {
+			if !strings.HasSuffix(b.String(), "\n\n") {
+				b.WriteString("\n") // Pieces of a split file end mid-file
+			}
+			b.WriteString(partFooter(i+1, len(groups)))
+		}
+		parts[i].text = b.String()
+	}
+	return parts
+}
+
+// splitOversized cuts a block of the given size, larger than budget, at line
+// boundaries and returns the pieces with their sizes. Pieces after the first
+// repeat the file banner, marked as continued. Lines are sized by the block's
+// tokens per byte instead of being tokenized one by one; a piece that turns
+// out too large is cut again. A single line larger than budget becomes a
+// piece of its own.
+func splitOversized(b splitBlock, size partSize, budget SplitLimit, measure func(string) partSize) ([]splitBlock, []partSize) {
+	continued := ""
+	if b.path != "" {
+		continued = fileSeparator(b.path+" (continued)") + "\n"
+	}
+	estimate := func(text string) partSize {
+		return partSize{bytes: len(text), tokens: (len(text)*size.tokens + size.bytes - 1) / max(size.bytes, 1)}
+	}
+
+	banner, body := cutBanner(b.text)
+	var texts []string
+	var current strings.Builder
+	current.WriteString(banner)
+	currentSize := estimate(banner)
+	hasLines := false // current holds more than a banner
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if line == "" {
+			continue
+		}
+		lineSize := estimate(line)
+		if hasLines && !currentSize.add(lineSize).within(budget) {
+			texts = append(texts, current.String())
+			current.Reset()
+			current.WriteString(continued)
+			currentSize = estimate(continued)
+			hasLines = false
+		}
+		current.WriteString(line)
+		currentSize = currentSize.add(lineSize)
+		hasLines = true
+	}
+	if hasLines {
+		texts = append(texts, current.String())
+	}
+
+	var pieces []splitBlock
+	var sizes []partSize
+	for _, text := range texts {
+		piece, pieceSize := splitBlock{path: b.path, text: text}, measure(text)
+		if !pieceSize.within(budget) && text != b.text {
+			subPieces, subSizes := splitOversized(piece, pieceSize, budget, measure)
+			pieces, sizes = append(pieces, subPieces...), append(sizes, subSizes...)
+			continue
+		}
+		pieces, sizes = append(pieces, piece), append(sizes, pieceSize)
+	}
+	return pieces, sizes
+}
+
+// cutBanner splits the file banner (see fileSeparator), and the blank line
+// buildBundle writes after it, off the start of text.
+func cutBanner(text string) (banner, rest string) {
+	rule, _, _ := strings.Cut(fileSeparator(""), "\n")
+	if !strings.HasPrefix(text, rule+"\nFILE: ") {
+		return "", text
+	}
+	end := strings.Index(text[len(rule)+1:], "\n"+rule+"\n")
+	if end < 0 {
+		return "", text
+	}
+	end += len(rule) + 1 + len(rule) + 2
+	if strings.HasPrefix(text[end:], "\n") {
+		end++
+	}
+	return text[:end], text[end:]
+}
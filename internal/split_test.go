@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplitBundle(t *testing.T) {
+	var long strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&long, "line %03d of a long file\n", i)
+	}
+	files := map[string]string{
+		"a.go":    "package a\n",
+		"b.go":    "package b\n",
+		"long.go": long.String(),
+	}
+
+	tests := []struct {
+		name  string
+		limit SplitLimit
+		split bool
+	}{
+		{"off", SplitLimit{}, false},
+		{"fits", SplitLimit{Bytes: 100000}, false},
+		{"bytes", SplitLimit{Bytes: 1500}, true},
+		{"tokens", SplitLimit{Tokens: 400}, true},
+		{"both", SplitLimit{Tokens: 1000, Bytes: 1000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, files)
+			app.splitLimit = tt.limit
+			result := app.buildBundle([]string{"a.go", "long.go", "b.go"})
+			parts := app.splitBundle(result)
+			if split := len(parts) > 1; split != tt.split {
+				t.Fatalf("%d part(s), want a split: %v", len(parts), tt.split)
+			}
+
+			measure := app.sizer()
+			var joined strings.Builder
+			for i, part := range parts {
+				if size := measure(part.text); !size.within(tt.limit) {
+					t.Errorf("part %d is %+v, over the limit %+v", i+1, size, tt.limit)
+				}
+				joined.WriteString(part.text)
+			}
+			for i := range 200 {
+				if line := fmt.Sprintf("line %03d of", i); tt.split && strings.Count(joined.String(), line) != 1 {
+					t.Errorf("%q appears %d times in the parts", line, strings.Count(joined.String(), line))
+				}
+			}
+		})
+	}
+}
+
+func TestDropStaleBundleParts(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(app *App)
+		stale  bool
+	}{
+		{"unchanged", func(app *App) {}, false},
+		{"selection", func(app *App) { app.selectedFiles["b.go"] = true }, true},
+		{"range", func(app *App) { app.addLineRange("a.go", LineRange{Start: 1, End: 1}) }, true},
+		{"transforms", func(app *App) { app.transforms.StripComments = true }, true},
+		{"line numbers", func(app *App) { app.lineNumbers = true }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, map[string]string{"a.go": "package a\n", "b.go": "package b\n"})
+			app.fileList = []string{"a.go", "b.go"}
+			app.selectedFiles["a.go"] = true
+			app.bundleParts = []bundlePart{{text: "part 1"}, {text: "part 2"}}
+			app.bundlePart = 1
+			app.partsKey = app.bundleStateKey()
+
+			tt.change(app)
+			app.dropStaleBundleParts()
+			if stale := app.bundleParts == nil; stale != tt.stale {
+				t.Errorf("parts dropped: %v, want %v", stale, tt.stale)
+			}
+		})
+	}
+}
+
+// TestSplitOversizedUnevenLines checks that pieces stay within the budget
+// when lines are far denser in tokens than the block on average.
+func TestSplitOversizedUnevenLines(t *testing.T) {
+	// One token per x, and one per 8 other bytes.
+	measure := func(text string) partSize {
+		xs := strings.Count(text, "x")
+		return partSize{bytes: len(text), tokens: xs + (len(text)-xs)/8}
+	}
+	var b strings.Builder
+	for i := range 100 {
+		if i%10 == 0 {
+			b.WriteString(strings.Repeat("x", 60) + "\n")
+		} else {
+			b.WriteString(strings.Repeat(".", 60) + "\n")
+		}
+	}
+	block := splitBlock{path: "f.go", text: b.String()}
+	budget := SplitLimit{Tokens: 100}
+
+	pieces, sizes := splitOversized(block, measure(block.text), budget, measure)
+	lines := 0
+	for i, piece := range pieces {
+		if sizes[i] != measure(piece.text) {
+			t.Errorf("piece %d: size %+v, measured %+v", i+1, sizes[i], measure(piece.text))
+		}
+		if !sizes[i].within(budget) {
+			t.Errorf("piece %d is %+v, over the budget %+v", i+1, sizes[i], budget)
+		}
+		lines += strings.Count(strings.TrimPrefix(piece.text, fileSeparator("f.go (continued)")+"\n"), "\n")
+	}
+	if lines != 100 {
+		t.Errorf("the pieces hold %d lines, want 100", lines)
+	}
+}
+
+// TestSplitOversizedLongLine checks that a line larger than the budget gets a
+// piece of its own, with its banner, and no piece holds only a banner.
+func TestSplitOversizedLongLine(t *testing.T) {
+	measure := func(text string) partSize { return partSize{bytes: len(text), tokens: len(text) / 4} }
+	long := strings.Repeat("x", 500) + "\n"
+	tests := []struct {
+		name string
+		body string
+		want []string // Content of each piece, without banners
+	}{
+		{"only line", long, []string{long}},
+		{"first line", long + "short\n", []string{long, "short\n"}},
+		{"middle line", "short\n" + long + "short\n", []string{"short\n", long, "short\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := splitBlock{path: "f.go", text: fileSeparator("f.go") + "\n" + tt.body}
+			budget := SplitLimit{Bytes: 200}
+			pieces, _ := splitOversized(block, measure(block.text), budget, measure)
+			if len(pieces) != len(tt.want) {
+				t.Fatalf("%d pieces, want %d", len(pieces), len(tt.want))
+			}
+			for i, piece := range pieces {
+				banner, content := cutBanner(piece.text)
+				if banner == "" || content != tt.want[i] {
+					t.Errorf("piece %d = %q, want a banner and %q", i+1, piece.text, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// PromptTemplateExt is the file extension of prompt templates.
+const PromptTemplateExt = ".tmpl"
+
+// TemplateData is the data prompt templates are executed with.
+type TemplateData struct {
+	Roots       []string       // Absolute root directories
+	Branch      string         // Git branch of the first root, "" outside a repository
+	Files       []TemplateFile // Bundled files, in bundle order
+	Tree        string         // Directory tree of the bundled files
+	Bundle      string         // The plain bundle without the instruction
+	Instruction string         // Task description from the instruction editor, "" if none
+	Chars       int            // Characters of all file contents
+	Tokens      int            // Tokens of all file contents (0 if the tokenizer is unavailable)
+}
+
+// TemplateFile is one bundled file as seen by prompt templates.
+type TemplateFile struct {
+	Path    string
+	Content string // Content as bundled: transformed, line-ranged and redacted
+	Tokens  int
+	Error   string // Read error, if the file could not be read
+}
+
+// promptTemplate is a template file found on disk.
+type promptTemplate struct {
+	name   string // File name without PromptTemplateExt
+	path   string
+	origin string // "global" or the label of the root it came from
+}
+
+// promptTemplateDirs returns the directories searched for templates, in
+// increasing precedence: the user's config directory, then each root's
+// .grepforllm/templates directory.
+func (app *App) promptTemplateDirs() (dirs, origins []string) {
+	if configDir, err := getConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "templates"))
+		origins = append(origins, "global")
+	}
+	for _, root := range app.roots {
+		dirs = append(dirs, filepath.Join(root.Dir, ".grepforllm", "templates"))
+		origins = append(origins, root.Label)
+	}
+	return dirs, origins
+}
+
+// findPromptTemplates lists the available templates by name. A repository
+// template replaces a global template of the same name.
+func (app *App) findPromptTemplates() []promptTemplate {
+	dirs, origins := app.promptTemplateDirs()
+	byName := make(map[string]promptTemplate)
+	for i, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // Missing directories are normal
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), PromptTemplateExt)
+			if !ok || entry.IsDir() {
+				continue
+			}
+			byName[name] = promptTemplate{name: name, path: filepath.Join(dir, entry.Name()), origin: origins[i]}
+		}
+	}
+
+	templates := make([]promptTemplate, 0, len(byName))
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].name < templates[j].name })
+	return templates
+}
+
+// templateData collects the TemplateData for a built bundle.
+func (app *App) templateData(result bundle) TemplateData {
+	app.mutex.Lock()
+	tokenizer := app.tokenizer
+	instruction := app.instruction
+	app.mutex.Unlock()
+	roots := app.RootDirs() // Roots never change after NewApp
+
+	data := TemplateData{Roots: roots, Bundle: result.content, Instruction: instruction}
+	if len(roots) > 0 {
+		data.Branch = gitBranch(roots[0])
+	}
+
+	paths := make([]string, 0, len(result.entries))
+	for _, entry := range result.entries {
+		file := TemplateFile{Path: entry.path, Content: entry.content}
+		if entry.err != nil {
+			file.Error = entry.err.Error()
+		}
+		if tokenizer != nil {
+			file.Tokens = len(tokenizer.Encode(entry.content, nil, nil))
+		}
+		data.Files = append(data.Files, file)
+		data.Chars += len(entry.content)
+		data.Tokens += file.Tokens
+		paths = append(paths, entry.path)
+	}
+	data.Tree = renderTree(paths)
+	return data
+}
+
+// renderPromptTemplate executes the template file t for a built bundle.
+func (app *App) renderPromptTemplate(t promptTemplate, result bundle) (string, error) {
+	source, err := os.ReadFile(t.path)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(t.name).Parse(string(source))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, app.templateData(result)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// renderTree draws slash-separated paths as an indented tree, like tree(1).
+func renderTree(paths []string) string {
+	type node struct {
+		children map[string]*node
+		names    []string
+	}
+	newNode := func() *node { return &node{children: make(map[string]*node)} }
+
+	root := newNode()
+	for _, p := range paths {
+		n := root
+		for _, part := range strings.Split(p, "/") {
+			child, ok := n.children[part]
+			if !ok {
+				child = newNode()
+				n.children[part] = child
+				n.names = append(n.names, part)
+			}
+			n = child
+		}
+	}
+
+	var b strings.Builder
+	var walk func(n *node, indent string)
+	walk = func(n *node, indent string) {
+		names := append([]string(nil), n.names...)
+		sort.Strings(names)
+		for i, name := range names {
+			branch, next := "├── ", "│   "
+			if i == len(names)-1 {
+				branch, next = "└── ", "    "
+			}
+			child := n.children[name]
+			if len(child.names) > 0 {
+				name += "/"
+			}
+			fmt.Fprintf(&b, "%s%s%s\n", indent, branch, name)
+			walk(child, indent+next)
+		}
+	}
+	walk(root, "")
+	return b.String()
+}
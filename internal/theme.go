@@ -0,0 +1,316 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Theme holds the colours of the UI. Each role is a gocui attribute: a
+// colour, effects such as bold, or both. Roles painted into view text
+// (selected files, marked lines, diffs) are converted to ANSI escapes.
+type Theme struct {
+	FrameFocused  gocui.Attribute // Frame of the focused view and of overlays
+	FrameBlurred  gocui.Attribute // Frame of the other views
+	Text          gocui.Attribute // Overlays, help and status bar
+	Path          gocui.Attribute // Root directory in the path view
+	Cursor        gocui.Attribute // Line under the cursor in lists
+	CursorBg      gocui.Attribute
+	Highlight     gocui.Attribute // Cursor line of views without their own (gocui's SelFgColor)
+	Filter        gocui.Attribute // Filter patterns
+	FilterEditing gocui.Attribute // Filter patterns while typing
+	Loading       gocui.Attribute
+	Error         gocui.Attribute
+	Selected      gocui.Attribute // Selected files in the Files view
+	CopyFlash     gocui.Attribute // Files just copied
+	Marked        gocui.Attribute // Marked line ranges in the Content view
+	Visual        gocui.Attribute // Lines being marked
+	Heading       gocui.Attribute // Group and file headings
+	DiffAdded     gocui.Attribute
+	DiffRemoved   gocui.Attribute
+	DiffHunk      gocui.Attribute
+
+	// SyntaxTheme is the chroma style matching the theme, used unless
+	// "syntaxTheme" is configured. "none" disables highlighting.
+	SyntaxTheme string
+}
+
+// ThemeMonochrome is the built-in theme used when NO_COLOR is set.
+const ThemeMonochrome = "monochrome"
+
+// builtinThemes are the themes available by name.
+var builtinThemes = map[string]Theme{
+	"default": {
+		FrameFocused:  gocui.ColorGreen,
+		FrameBlurred:  gocui.ColorBlue,
+		Text:          gocui.ColorWhite,
+		Path:          gocui.ColorMagenta,
+		Cursor:        gocui.ColorCyan | gocui.AttrBold,
+		CursorBg:      gocui.ColorDefault,
+		Highlight:     gocui.ColorMagenta,
+		Filter:        gocui.ColorCyan,
+		FilterEditing: gocui.ColorWhite | gocui.AttrBold,
+		Loading:       gocui.ColorCyan,
+		Error:         gocui.ColorRed,
+		Selected:      gocui.ColorGreen,
+		CopyFlash:     gocui.AttrReverse,
+		Marked:        gocui.ColorGreen,
+		Visual:        gocui.AttrReverse,
+		Heading:       gocui.AttrBold,
+		DiffAdded:     gocui.ColorGreen,
+		DiffRemoved:   gocui.ColorRed,
+		DiffHunk:      gocui.ColorCyan,
+		SyntaxTheme:   DefaultSyntaxTheme,
+	},
+	// Dark colours from the 256-colour palette, readable on white.
+	"light-terminal": {
+		FrameFocused:  gocui.Get256Color(28) | gocui.AttrBold,
+		FrameBlurred:  gocui.Get256Color(244),
+		Text:          gocui.ColorDefault,
+		Path:          gocui.Get256Color(90),
+		Cursor:        gocui.Get256Color(25) | gocui.AttrBold,
+		CursorBg:      gocui.ColorDefault,
+		Highlight:     gocui.Get256Color(90),
+		Filter:        gocui.Get256Color(25),
+		FilterEditing: gocui.AttrBold,
+		Loading:       gocui.Get256Color(25),
+		Error:         gocui.Get256Color(124),
+		Selected:      gocui.Get256Color(28),
+		CopyFlash:     gocui.AttrReverse,
+		Marked:        gocui.Get256Color(28),
+		Visual:        gocui.AttrReverse,
+		Heading:       gocui.AttrBold,
+		DiffAdded:     gocui.Get256Color(28),
+		DiffRemoved:   gocui.Get256Color(124),
+		DiffHunk:      gocui.Get256Color(25),
+		SyntaxTheme:   "github",
+	},
+	"high-contrast": {
+		FrameFocused:  gocui.ColorYellow | gocui.AttrBold,
+		FrameBlurred:  gocui.ColorWhite,
+		Text:          gocui.ColorWhite | gocui.AttrBold,
+		Path:          gocui.ColorWhite | gocui.AttrBold,
+		Cursor:        gocui.ColorBlack | gocui.AttrBold,
+		CursorBg:      gocui.ColorYellow,
+		Highlight:     gocui.ColorYellow | gocui.AttrBold,
+		Filter:        gocui.ColorWhite,
+		FilterEditing: gocui.ColorYellow | gocui.AttrBold,
+		Loading:       gocui.ColorWhite | gocui.AttrBold,
+		Error:         gocui.ColorRed | gocui.AttrBold,
+		Selected:      gocui.ColorYellow | gocui.AttrBold,
+		CopyFlash:     gocui.AttrReverse,
+		Marked:        gocui.ColorYellow | gocui.AttrUnderline,
+		Visual:        gocui.AttrReverse,
+		Heading:       gocui.ColorWhite | gocui.AttrBold | gocui.AttrUnderline,
+		DiffAdded:     gocui.ColorGreen | gocui.AttrBold,
+		DiffRemoved:   gocui.ColorRed | gocui.AttrBold,
+		DiffHunk:      gocui.ColorCyan | gocui.AttrBold,
+		SyntaxTheme:   DefaultSyntaxTheme,
+	},
+	// Attributes only, for NO_COLOR.
+	ThemeMonochrome: {
+		FrameFocused:  gocui.AttrBold,
+		FrameBlurred:  gocui.ColorDefault,
+		Text:          gocui.ColorDefault,
+		Path:          gocui.AttrBold,
+		Cursor:        gocui.AttrReverse,
+		CursorBg:      gocui.ColorDefault,
+		Highlight:     gocui.AttrReverse,
+		Filter:        gocui.ColorDefault,
+		FilterEditing: gocui.AttrBold,
+		Loading:       gocui.ColorDefault,
+		Error:         gocui.AttrBold,
+		Selected:      gocui.AttrBold,
+		CopyFlash:     gocui.AttrReverse,
+		Marked:        gocui.AttrUnderline,
+		Visual:        gocui.AttrReverse,
+		Heading:       gocui.AttrBold,
+		DiffAdded:     gocui.AttrBold,
+		DiffRemoved:   gocui.AttrDim,
+		DiffHunk:      gocui.AttrUnderline,
+		SyntaxTheme:   "none",
+	},
+}
+
+// roles maps the role names used in config.json to the fields of t.
+func (t *Theme) roles() map[string]*gocui.Attribute {
+	return map[string]*gocui.Attribute{
+		"frameFocused":  &t.FrameFocused,
+		"frameBlurred":  &t.FrameBlurred,
+		"text":          &t.Text,
+		"path":          &t.Path,
+		"cursor":        &t.Cursor,
+		"cursorBg":      &t.CursorBg,
+		"highlight":     &t.Highlight,
+		"filter":        &t.Filter,
+		"filterEditing": &t.FilterEditing,
+		"loading":       &t.Loading,
+		"error":         &t.Error,
+		"selected":      &t.Selected,
+		"copyFlash":     &t.CopyFlash,
+		"marked":        &t.Marked,
+		"visual":        &t.Visual,
+		"heading":       &t.Heading,
+		"diffAdded":     &t.DiffAdded,
+		"diffRemoved":   &t.DiffRemoved,
+		"diffHunk":      &t.DiffHunk,
+	}
+}
+
+// NoColor reports whether the NO_COLOR convention asks for output without
+// colours (https://no-color.org).
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// LoadTheme returns the theme called name: a built-in or one defined in
+// custom, which maps role names to styles and may start from a built-in
+// with "base". The empty name is the default theme.
+func LoadTheme(name string, custom map[string]map[string]string) (Theme, error) {
+	if name == "" {
+		name = "default"
+	}
+	if theme, ok := builtinThemes[name]; ok {
+		return theme, nil
+	}
+	spec, ok := custom[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (expected %s, or one defined in \"themes\" in config.json)", name, strings.Join(themeNames(custom), ", "))
+	}
+
+	base := spec["base"]
+	if base == "" {
+		base = "default"
+	}
+	theme, ok := builtinThemes[base]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme %q", name, base)
+	}
+	roles := theme.roles()
+	for role, style := range spec {
+		switch role {
+		case "base":
+			continue
+		case "syntaxTheme":
+			theme.SyntaxTheme = style
+			continue
+		}
+		field, ok := roles[role]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %s: unknown role %q", name, role)
+		}
+		attr, err := parseStyle(style)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme %s: %s: %w", name, role, err)
+		}
+		*field = attr
+	}
+	return theme, nil
+}
+
+// themeNames lists the built-in and custom theme names.
+func themeNames(custom map[string]map[string]string) []string {
+	var names []string
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var colorNames = map[string]gocui.Attribute{
+	"default": gocui.ColorDefault,
+	"black":   gocui.ColorBlack,
+	"red":     gocui.ColorRed,
+	"green":   gocui.ColorGreen,
+	"yellow":  gocui.ColorYellow,
+	"blue":    gocui.ColorBlue,
+	"magenta": gocui.ColorMagenta,
+	"cyan":    gocui.ColorCyan,
+	"white":   gocui.ColorWhite,
+}
+
+var effectNames = map[string]gocui.Attribute{
+	"bold":      gocui.AttrBold,
+	"dim":       gocui.AttrDim,
+	"italic":    gocui.AttrItalic,
+	"underline": gocui.AttrUnderline,
+	"reverse":   gocui.AttrReverse,
+	"blink":     gocui.AttrBlink,
+}
+
+// parseStyle parses a style such as "bold cyan", "208", "#ff8700 underline"
+// or "reverse": at most one colour (a name, a 256-colour index or #rrggbb)
+// and any effects.
+func parseStyle(style string) (gocui.Attribute, error) {
+	var attr, color gocui.Attribute
+	hasColor := false
+	for _, word := range strings.Fields(strings.ToLower(style)) {
+		if effect, ok := effectNames[word]; ok {
+			attr |= effect
+			continue
+		}
+		var c gocui.Attribute
+		if named, ok := colorNames[word]; ok {
+			c = named
+		} else if n, err := strconv.Atoi(word); err == nil && n >= 0 && n <= 255 {
+			c = gocui.Get256Color(int32(n))
+		} else if hex, ok := strings.CutPrefix(word, "#"); ok && len(hex) == 6 {
+			rgb, err := strconv.ParseInt(hex, 16, 32)
+			if err != nil {
+				return 0, fmt.Errorf("invalid colour %q", word)
+			}
+			c = gocui.GetRGBColor(int32(rgb))
+		} else {
+			return 0, fmt.Errorf("invalid style word %q (expected a colour name, 0-255, #rrggbb, bold, dim, italic, underline, reverse or blink)", word)
+		}
+		if hasColor {
+			return 0, fmt.Errorf("more than one colour in %q", style)
+		}
+		color, hasColor = c, true
+	}
+	return color | attr, nil
+}
+
+// ansi returns the escape sequence selecting attr in view text. gocui parses
+// one colour per sequence and a colour resets the effects, so the colour
+// comes first; RGB colours are approximated by the 256-colour palette.
+func ansi(attr gocui.Attribute) string {
+	var b strings.Builder
+	if color := attr & gocui.AttrColorBits; color != gocui.ColorDefault && color.IsValidColor() {
+		r, g, bl := color.RGB()
+		switch index := int(color &^ (gocui.AttrIsValidColor | gocui.AttrIsRGBColor)); {
+		case color&gocui.AttrIsRGBColor != 0:
+			fmt.Fprintf(&b, "\x1b[38;5;%dm", xterm256(uint8(r), uint8(g), uint8(bl)))
+		case index < 8:
+			fmt.Fprintf(&b, "\x1b[%dm", 30+index)
+		default:
+			fmt.Fprintf(&b, "\x1b[38;5;%dm", index)
+		}
+	}
+	for _, effect := range []struct {
+		attr gocui.Attribute
+		code int
+	}{{gocui.AttrBold, 1}, {gocui.AttrDim, 2}, {gocui.AttrItalic, 3}, {gocui.AttrUnderline, 4}, {gocui.AttrBlink, 5}, {gocui.AttrReverse, 7}} {
+		if attr&effect.attr != 0 {
+			fmt.Fprintf(&b, "\x1b[%dm", effect.code)
+		}
+	}
+	return b.String()
+}
+
+// paint returns text styled with attr for view text.
+func paint(attr gocui.Attribute, text string) string {
+	escape := ansi(attr)
+	if escape == "" {
+		return text
+	}
+	return escape + text + "\x1b[0m"
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		style   string
+		want    gocui.Attribute
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"red", gocui.ColorRed, false},
+		{" Bold  Green ", gocui.ColorGreen | gocui.AttrBold, false},
+		{"underline dim", gocui.AttrUnderline | gocui.AttrDim, false},
+		{"208", gocui.Get256Color(208), false},
+		{"#ff8800 italic", gocui.GetRGBColor(0xff8800) | gocui.AttrItalic, false},
+		{"256", 0, true},
+		{"#ff88", 0, true},
+		{"#gg0000", 0, true},
+		{"red blue", 0, true},
+		{"sparkly", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseStyle(tt.style)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseStyle(%q) = %v, %v; want %v, error %v", tt.style, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Transforms selects the optional content transforms applied between reading a
+// file and appending it to the bundle.
+type Transforms struct {
+	StripComments bool `json:"stripComments,omitempty"`      // Remove line and block comments
+	CollapseBlank bool `json:"collapseBlankLines,omitempty"` // Collapse runs of blank lines into one
+	TrimTrailing  bool `json:"trimTrailing,omitempty"`       // Strip trailing spaces and tabs
+	StripLicense  bool `json:"stripLicense,omitempty"`       // Remove a leading license/copyright comment
+}
+
+// transformNames maps the names accepted by ParseTransforms to their fields.
+var transformNames = []string{"comments", "blank", "trailing", "license"}
+
+// Any reports whether at least one transform is enabled.
+func (t Transforms) Any() bool {
+	return t.StripComments || t.CollapseBlank || t.TrimTrailing || t.StripLicense
+}
+
+// String lists the enabled transforms in ParseTransforms format.
+func (t Transforms) String() string {
+	var names []string
+	for i, enabled := range []bool{t.StripComments, t.CollapseBlank, t.TrimTrailing, t.StripLicense} {
+		if enabled {
+			names = append(names, transformNames[i])
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseTransforms parses a comma-separated list of transform names
+// (comments, blank, trailing, license, all or none).
+func ParseTransforms(spec string) (Transforms, error) {
+	var t Transforms
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "", "none":
+		case "comments":
+			t.StripComments = true
+		case "blank":
+			t.CollapseBlank = true
+		case "trailing":
+			t.TrimTrailing = true
+		case "license":
+			t.StripLicense = true
+		case "all":
+			t = Transforms{StripComments: true, CollapseBlank: true, TrimTrailing: true, StripLicense: true}
+		default:
+			return Transforms{}, fmt.Errorf("unknown transform %q (expected %s, all or none)", name, strings.Join(transformNames, ", "))
+		}
+	}
+	return t, nil
+}
+
+// numberedLine is a line of output together with its 1-based line number in
+// the original file, so line numbers and ranges survive removed lines.
+type numberedLine struct {
+	no   int
+	text string
+}
+
+// commentSyntax describes comments and string literals of a language, enough
+// to find comments without being fooled by comment markers inside strings.
+type commentSyntax struct {
+	lineComments     []string // Markers starting a comment that runs to end of line
+	blockStart       string   // Block comment opener, empty if none
+	blockEnd         string
+	nestedBlocks     bool   // Block comments nest (Rust)
+	quotes           string // Quote characters of string literals with backslash escapes
+	rawQuotes        string // Quote characters of literals without escapes
+	tripleQuotes     bool   // Python """ and ''' strings
+	multilineStrings bool   // Quoted strings may span lines
+	charLiterals     bool   // ' starts a char literal only if it closes right away (Rust lifetimes)
+	hashAtWordStart  bool   // # starts a comment only at the start of a word (shell)
+	heredocs         bool   // <<WORD starts a literal from the next line to a line holding WORD (shell)
+	keepShebang      bool   // A #! first line is not a comment
+}
+
+var (
+	cLikeSyntax = &commentSyntax{
+		lineComments: []string{"//"},
+		blockStart:   "/*",
+		blockEnd:     "*/",
+		quotes:       "\"'",
+	}
+	goSyntax = &commentSyntax{
+		lineComments: []string{"//"},
+		blockStart:   "/*",
+		blockEnd:     "*/",
+		quotes:       "\"'",
+		rawQuotes:    "`",
+	}
+	jsSyntax = &commentSyntax{
+		lineComments: []string{"//"},
+		blockStart:   "/*",
+		blockEnd:     "*/",
+		quotes:       "\"'`",
+	}
+	rustSyntax = &commentSyntax{
+		lineComments:     []string{"//"},
+		blockStart:       "/*",
+		blockEnd:         "*/",
+		nestedBlocks:     true,
+		quotes:           "\"",
+		multilineStrings: true,
+		charLiterals:     true,
+	}
+	pythonSyntax = &commentSyntax{
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		tripleQuotes: true,
+		keepShebang:  true,
+	}
+	shellSyntax = &commentSyntax{
+		lineComments:     []string{"#"},
+		quotes:           "\"",
+		rawQuotes:        "'",
+		multilineStrings: true,
+		hashAtWordStart:  true,
+		heredocs:         true,
+		keepShebang:      true,
+	}
+)
+
+// commentSyntaxes maps lowercase file extensions to their comment syntax.
+var commentSyntaxes = map[string]*commentSyntax{
+	".go":    goSyntax,
+	".js":    jsSyntax,
+	".jsx":   jsSyntax,
+	".mjs":   jsSyntax,
+	".cjs":   jsSyntax,
+	".ts":    jsSyntax,
+	".tsx":   jsSyntax,
+	".rs":    rustSyntax,
+	".py":    pythonSyntax,
+	".pyi":   pythonSyntax,
+	".sh":    shellSyntax,
+	".bash":  shellSyntax,
+	".zsh":   shellSyntax,
+	".ksh":   shellSyntax,
+	".c":     cLikeSyntax,
+	".h":     cLikeSyntax,
+	".cc":    cLikeSyntax,
+	".cpp":   cLikeSyntax,
+	".hpp":   cLikeSyntax,
+	".java":  cLikeSyntax,
+	".cs":    cLikeSyntax,
+	".kt":    cLikeSyntax,
+	".swift": cLikeSyntax,
+	".scala": cLikeSyntax,
+}
+
+// syntaxFor returns the comment syntax for relPath, or nil if unknown.
+func syntaxFor(relPath string) *commentSyntax {
+	return commentSyntaxes[strings.ToLower(filepath.Ext(relPath))]
+}
+
+// span is a byte range [start, end) of the source.
+type span struct {
+	start, end int
+}
+
+// findComments returns the comments in src, in order. Line comments end before
+// their newline; block comments include their delimiters.
+func findComments(src string, syn *commentSyntax) []span {
+	var spans []span
+	n := len(src)
+	i := 0
+	if syn.keepShebang && strings.HasPrefix(src, "#!") {
+		i = lineEnd(src, 0)
+	}
+	var heredocs []heredoc // Opened on the current line, bodies start on the next
+
+	for i < n {
+		rest := src[i:]
+		c := src[i]
+
+		if c == '\n' && len(heredocs) > 0 {
+			i = skipHeredocs(src, i+1, heredocs)
+			heredocs = nil
+			continue
+		}
+		if syn.heredocs && strings.HasPrefix(rest, "<<") {
+			if h, end, ok := parseHeredoc(src, i); ok {
+				heredocs = append(heredocs, h)
+				i = end
+				continue
+			}
+		}
+
+		if syn.tripleQuotes && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''")) {
+			if j := strings.Index(rest[3:], rest[:3]); j >= 0 {
+				i += 3 + j + 3
+			} else {
+				i = n
+			}
+			continue
+		}
+
+		if syn.blockStart != "" && strings.HasPrefix(rest, syn.blockStart) {
+			end := scanBlockComment(src, i, syn)
+			spans = append(spans, span{i, end})
+			i = end
+			continue
+		}
+
+		if isLineComment(src, i, syn) {
+			end := lineEnd(src, i)
+			spans = append(spans, span{i, end})
+			i = end
+			continue
+		}
+
+		switch {
+		case strings.IndexByte(syn.quotes, c) >= 0 && !(syn.charLiterals && c == '\''):
+			i = skipQuoted(src, i, true, syn.multilineStrings)
+		case strings.IndexByte(syn.rawQuotes, c) >= 0:
+			i = skipQuoted(src, i, false, true)
+		case syn.charLiterals && c == '\'' && isCharLiteral(src, i):
+			i = skipQuoted(src, i, true, false)
+		default:
+			i++
+		}
+	}
+	return spans
+}
+
+// isLineComment reports whether a line comment starts at src[i].
+func isLineComment(src string, i int, syn *commentSyntax) bool {
+	for _, marker := range syn.lineComments {
+		if !strings.HasPrefix(src[i:], marker) {
+			continue
+		}
+		if syn.hashAtWordStart && i > 0 {
+			// In shell, $# and foo#bar are not comments.
+			prev := src[i-1]
+			if prev != ' ' && prev != '\t' && prev != '\n' && prev != ';' {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// lineEnd returns the index of the newline ending the line containing src[i],
+// or len(src).
+func lineEnd(src string, i int) int {
+	if j := strings.IndexByte(src[i:], '\n'); j >= 0 {
+		return i + j
+	}
+	return len(src)
+}
+
+// scanBlockComment returns the end of the block comment starting at src[i].
+func scanBlockComment(src string, i int, syn *commentSyntax) int {
+	depth := 0
+	for i < len(src) {
+		switch {
+		case strings.HasPrefix(src[i:], syn.blockStart) && (depth == 0 || syn.nestedBlocks):
+			depth++
+			i += len(syn.blockStart)
+		case strings.HasPrefix(src[i:], syn.blockEnd):
+			depth--
+			i += len(syn.blockEnd)
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(src) // Unterminated: comment runs to end of file
+}
+
+// skipQuoted returns the index just past the string literal starting at src[i].
+// Unless multiline, an unterminated literal ends at the newline.
+func skipQuoted(src string, i int, escapes, multiline bool) int {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch {
+		case escapes && src[j] == '\\':
+			j++ // Skip the escaped character
+		case src[j] == quote:
+			return j + 1
+		case src[j] == '\n' && !multiline:
+			return j
+		}
+	}
+	return len(src)
+}
+
+// heredoc is a shell here-document whose body ends at a line holding word.
+type heredoc struct {
+	word      string
+	stripTabs bool // <<- allows the terminator to be indented with tabs
+}
+
+// parseHeredoc parses the here-document operator at src[i], such as <<EOF,
+// <<-EOF or <<'EOF', and returns the index just past it. Here-strings (<<<)
+// and shifts such as $((1<<2)) are not here-documents.
+func parseHeredoc(src string, i int) (heredoc, int, bool) {
+	j := i + 2
+	var h heredoc
+	if j < len(src) && src[j] == '<' {
+		return heredoc{}, 0, false
+	}
+	if j < len(src) && src[j] == '-' {
+		h.stripTabs = true
+		j++
+	}
+	for j < len(src) && (src[j] == ' ' || src[j] == '\t') {
+		j++
+	}
+	quote := byte(0)
+	if j < len(src) && (src[j] == '\'' || src[j] == '"') {
+		quote = src[j]
+		j++
+	}
+	start := j
+	for j < len(src) && (src[j] == '_' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= 'a' && src[j] <= 'z' || j > start && src[j] >= '0' && src[j] <= '9') {
+		j++
+	}
+	if j == start {
+		return heredoc{}, 0, false
+	}
+	h.word = src[start:j]
+	if quote != 0 {
+		if j >= len(src) || src[j] != quote {
+			return heredoc{}, 0, false
+		}
+		j++
+	}
+	return h, j, true
+}
+
+// skipHeredocs returns the end of the line terminating the last of heredocs,
+// whose bodies follow each other from src[i], or len(src).
+func skipHeredocs(src string, i int, heredocs []heredoc) int {
+	end := i
+	for _, h := range heredocs {
+		for {
+			if i >= len(src) {
+				return len(src)
+			}
+			end = lineEnd(src, i)
+			line := src[i:end]
+			if h.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			i = end + 1
+			if line == h.word {
+				break
+			}
+		}
+	}
+	return end
+}
+
+// isCharLiteral reports whether the ' at src[i] opens a char literal such as
+// 'x' or '\n', as opposed to a Rust lifetime like 'a.
+func isCharLiteral(src string, i int) bool {
+	if i+1 >= len(src) {
+		return false
+	}
+	if src[i+1] == '\\' {
+		return true
+	}
+	_, size := utf8.DecodeRuneInString(src[i+1:])
+	return i+1+size < len(src) && src[i+1+size] == '\''
+}
+
+// licenseHeader returns the leading comment block of src if it looks like a
+// license or copyright notice. The block ends at the first blank line.
+func licenseHeader(src string, spans []span, syn *commentSyntax) []span {
+	start := 0
+	if syn.keepShebang && strings.HasPrefix(src, "#!") {
+		start = lineEnd(src, 0)
+	}
+	if len(spans) == 0 || strings.TrimSpace(src[start:spans[0].start]) != "" {
+		return nil
+	}
+
+	block := spans[:1]
+	for _, next := range spans[1:] {
+		gap := src[block[len(block)-1].end:next.start]
+		if strings.TrimSpace(gap) != "" || strings.Count(gap, "\n") > 1 {
+			break
+		}
+		block = spans[:len(block)+1]
+	}
+
+	text := strings.ToLower(src[block[0].start:block[len(block)-1].end])
+	if strings.Contains(text, "copyright") || strings.Contains(text, "license") || strings.Contains(text, "spdx-license-identifier") {
+		return block
+	}
+	return nil
+}
+
+// transformLines splits content into numbered lines and applies t. Lines that
+// only held removed comments are dropped; the rest keep their original numbers.
+func transformLines(relPath, content string, t Transforms) []numberedLine {
+	original := splitLines(content)
+
+	// --- Comment and license removal (keeps newlines, so lines stay aligned) ---
+	stripped := original
+	if syn := syntaxFor(relPath); syn != nil && (t.StripComments || t.StripLicense) {
+		spans := findComments(content, syn)
+		license := licenseHeader(content, spans, syn)
+		remove := license
+		if t.StripComments {
+			remove = spans
+		}
+		if len(remove) > 0 {
+			stripped = splitLines(removeSpans(content, remove))
+		}
+		if len(license) > 0 {
+			// Also drop the blank line(s) that separated the license from the code.
+			for i := lineNumberAt(content, license[len(license)-1].end); i < len(stripped) && strings.TrimSpace(stripped[i]) == ""; i++ {
+				stripped[i] = ""
+				original[i] = " " // Treat as removed content so the line is dropped below
+			}
+		}
+	}
+
+	lines := make([]numberedLine, 0, len(stripped))
+	previousBlank := false
+	for i, text := range stripped {
+		if text != original[i] {
+			if strings.TrimSpace(text) == "" {
+				continue // The line only held a removed comment
+			}
+			text = strings.TrimRight(text, " \t")
+		}
+		if t.TrimTrailing {
+			text = strings.TrimRight(text, " \t")
+		}
+		blank := strings.TrimSpace(text) == ""
+		if t.CollapseBlank && blank && previousBlank {
+			continue
+		}
+		previousBlank = blank
+		lines = append(lines, numberedLine{no: i + 1, text: text})
+	}
+	return lines
+}
+
+// removeSpans returns src without the given spans, keeping any newlines they
+// contain so the line structure is unchanged.
+func removeSpans(src string, spans []span) string {
+	var b strings.Builder
+	pos := 0
+	for _, s := range spans {
+		b.WriteString(src[pos:s.start])
+		b.WriteString(strings.Repeat("\n", strings.Count(src[s.start:s.end], "\n")))
+		pos = s.end
+	}
+	b.WriteString(src[pos:])
+	return b.String()
+}
+
+// lineNumberAt returns the 0-based line index containing byte offset pos.
+func lineNumberAt(src string, pos int) int {
+	return strings.Count(src[:pos], "\n")
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindComments(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		src  string
+		want []string
+	}{
+		{"go raw string", "a.go", "s := `// not /* a */ comment`\n// real\n", []string{"// real"}},
+		{"go string", "a.go", `x := "/* no */" // yes`, []string{"// yes"}},
+		{"go rune", "a.go", `c := '"' // quote`, []string{"// quote"}},
+		{"go block", "a.go", "a /* one\ntwo */ b", []string{"/* one\ntwo */"}},
+		{"rust lifetime", "a.rs", "fn f<'a>(x: &'a str) -> &'a str { x } // c\n", []string{"// c"}},
+		{"rust char", "a.rs", `let c = '"'; // c`, []string{"// c"}},
+		{"rust nested block", "a.rs", "/* a /* b */ c */ x // d", []string{"/* a /* b */ c */", "// d"}},
+		{"rust multi-line string", "a.rs", "let s = \"a\n// no\";", nil},
+		{"python triple quotes", "a.py", "s = \"\"\"\n# not\n\"\"\"  # yes\n", []string{"# yes"}},
+		{"python single quotes", "a.py", "x = '#'  # c\n", []string{"# c"}},
+		{"python shebang", "a.py", "#!/usr/bin/env python\n# c\n", []string{"# c"}},
+		{"shell parameter length", "a.sh", "echo ${#x} $# a#b # c\n", []string{"# c"}},
+		{"shell quotes", "a.sh", "echo '# no' \"# no\" # yes\n", []string{"# yes"}},
+		{"shell heredoc", "a.sh", "cat <<EOF\n# heredoc\nEOF\n# c\n", []string{"# c"}},
+		{"shell quoted heredoc", "a.sh", "cat <<-'END' # c1\n\t# body\n\tEND\n# c2\n", []string{"# c1", "# c2"}},
+		{"shell two heredocs", "a.sh", "cat <<A <<B\n# a\nA\n# b\nB\n# c\n", []string{"# c"}},
+		{"shell unterminated heredoc", "a.sh", "cat <<EOF\n# body\n", nil},
+		{"shell shift", "a.sh", "echo $((1<<2)) # c\n# d\n", []string{"# c", "# d"}},
+		{"shell here-string", "a.sh", "cat <<<x # c\n", []string{"# c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range findComments(tt.src, syntaxFor(tt.path)) {
+				got = append(got, tt.src[s.start:s.end])
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("findComments(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		src        string
+		transforms Transforms
+		want       string // Lines as no:text
+	}{
+		{"none", "a.go", "a\n\nb\n", Transforms{}, "1:a 2: 3:b"},
+		{"comment lines removed", "a.go", "// c\na // c\nb\n", Transforms{StripComments: true}, "2:a 3:b"},
+		{"heredoc kept", "a.sh", "cat <<EOF\n# data\nEOF\n", Transforms{StripComments: true}, "1:cat_<<EOF 2:#_data 3:EOF"},
+		{"blank lines collapsed", "a.txt", "a\n\n\n\nb\n", Transforms{CollapseBlank: true}, "1:a 2: 5:b"},
+		{"trailing whitespace", "a.txt", "a \t\nb\n", Transforms{TrimTrailing: true}, "1:a 2:b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range transformLines(tt.path, tt.src, tt.transforms) {
+				got = append(got, strings.ReplaceAll(fmt.Sprintf("%d:%s", line.no, line.text), " ", "_"))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("transformLines = %s, want %s", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TruncateMode is how a large file is cut down when bundled.
+type TruncateMode string
+
+const (
+	TruncateNone     TruncateMode = ""         // Bundle the whole file
+	TruncateHead     TruncateMode = "head"     // First N lines
+	TruncateTail     TruncateMode = "tail"     // Last N lines
+	TruncateHeadTail TruncateMode = "headtail" // First N and last N lines, the middle elided
+	TruncateTokens   TruncateMode = "tokens"   // Leading lines up to N tokens
+)
+
+// Truncation is a truncation rule. The zero value bundles files whole.
+type Truncation struct {
+	Mode TruncateMode
+	N    int
+}
+
+// Active reports whether the rule cuts anything.
+func (t Truncation) Active() bool {
+	return t.Mode != TruncateNone
+}
+
+// String returns the rule in the form ParseTruncation accepts, "none" for
+// the zero value.
+func (t Truncation) String() string {
+	if !t.Active() {
+		return "none"
+	}
+	return fmt.Sprintf("%s:%d", t.Mode, t.N)
+}
+
+// Label describes the rule in the Files view and bundle headers.
+func (t Truncation) Label() string {
+	switch t.Mode {
+	case TruncateHead:
+		return fmt.Sprintf("head %d", t.N)
+	case TruncateTail:
+		return fmt.Sprintf("tail %d", t.N)
+	case TruncateHeadTail:
+		return fmt.Sprintf("head+tail %d", t.N)
+	case TruncateTokens:
+		return fmt.Sprintf("%d tokens", t.N)
+	}
+	return "full"
+}
+
+// ParseTruncation parses a rule such as "head:200", "tail:50",
+// "headtail:100" or "tokens:2000", or "none".
+func ParseTruncation(spec string) (Truncation, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" || spec == "none" {
+		return Truncation{}, nil
+	}
+	mode, count, ok := strings.Cut(spec, ":")
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || n <= 0 {
+		return Truncation{}, fmt.Errorf("invalid truncation %q (expected head:N, tail:N, headtail:N, tokens:N or none)", spec)
+	}
+	switch t := TruncateMode(strings.TrimSpace(mode)); t {
+	case TruncateHead, TruncateTail, TruncateHeadTail, TruncateTokens:
+		return Truncation{Mode: t, N: n}, nil
+	}
+	return Truncation{}, fmt.Errorf("invalid truncation mode %q (expected head, tail, headtail or tokens)", mode)
+}
+
+// truncationMarker stands in for cut lines in the bundle. It has no line
+// number (see renderLines).
+func truncationMarker(format string, args ...any) numberedLine {
+	return numberedLine{text: "[... " + fmt.Sprintf(format, args...) + " ...]"}
+}
+
+// truncateLines applies t to lines and reports whether anything was cut.
+// count measures the tokens of text for TruncateTokens.
+func truncateLines(lines []numberedLine, t Truncation, count func(string) int) ([]numberedLine, bool) {
+	switch t.Mode {
+	case TruncateHead:
+		if len(lines) <= t.N {
+			return lines, false
+		}
+		kept := append([]numberedLine(nil), lines[:t.N]...)
+		return append(kept, truncationMarker("%d more lines truncated", len(lines)-t.N)), true
+
+	case TruncateTail:
+		if len(lines) <= t.N {
+			return lines, false
+		}
+		kept := []numberedLine{truncationMarker("%d lines truncated", len(lines)-t.N)}
+		return append(kept, lines[len(lines)-t.N:]...), true
+
+	case TruncateHeadTail:
+		if len(lines) <= 2*t.N {
+			return lines, false
+		}
+		kept := append([]numberedLine(nil), lines[:t.N]...)
+		kept = append(kept, truncationMarker("%d lines elided", len(lines)-2*t.N))
+		return append(kept, lines[len(lines)-t.N:]...), true
+
+	case TruncateTokens:
+		measure := func(lines []numberedLine) partSize {
+			text := joinLines(lines)
+			return partSize{bytes: len(text), tokens: count(text)}
+		}
+		size := measure(lines)
+		if size.tokens <= t.N {
+			return lines, false
+		}
+		// Lines are sized by the tokens per byte of the text, as in
+		// splitOversized, and the kept lines measured once. While they are
+		// over the limit the cut is estimated again from their own density.
+		keep := len(lines)
+		for keep > 0 && size.tokens > t.N {
+			keep = min(keep-1, linesWithin(lines[:keep], t.N, size))
+			size = measure(lines[:keep])
+		}
+		kept := append([]numberedLine(nil), lines[:keep]...)
+		return append(kept, truncationMarker("truncated at %d tokens, %d more lines", t.N, len(lines)-keep)), true
+	}
+	return lines, false
+}
+
+// linesWithin returns how many leading lines fit in budget tokens, sizing
+// each line by the tokens per byte of size, the size of all lines.
+func linesWithin(lines []numberedLine, budget int, size partSize) int {
+	total := 0
+	for i, line := range lines {
+		total += len(line.text) + 1
+		if total*size.tokens > budget*max(size.bytes, 1) {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// joinLines returns the text of lines, each ending in a newline.
+func joinLines(lines []numberedLine) string {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line.text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// truncationFor returns the rule for relPath: its override from the Files
+// view, or the global rule. Assumes mutex is held.
+func (app *App) truncationFor(relPath string) Truncation {
+	if t, ok := app.truncations[relPath]; ok {
+		return t
+	}
+	return app.truncation
+}
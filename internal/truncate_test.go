@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseTruncation(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Truncation
+		wantErr bool
+	}{
+		{"", Truncation{}, false},
+		{"none", Truncation{}, false},
+		{"head:200", Truncation{TruncateHead, 200}, false},
+		{" Tail : 50 ", Truncation{TruncateTail, 50}, false},
+		{"headtail:10", Truncation{TruncateHeadTail, 10}, false},
+		{"tokens:2000", Truncation{TruncateTokens, 2000}, false},
+		{"head", Truncation{}, true},
+		{"head:0", Truncation{}, true},
+		{"head:-1", Truncation{}, true},
+		{"middle:5", Truncation{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTruncation(tt.spec)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTruncation(%q) = %v, %v; want %v, error %v", tt.spec, got, err, tt.want, tt.wantErr)
+		}
+		if err == nil {
+			if again, _ := ParseTruncation(got.String()); again != got {
+				t.Errorf("ParseTruncation(%q.String()) = %v, want %v", tt.spec, again, got)
+			}
+		}
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	numbered := func(n int) []numberedLine {
+		lines := make([]numberedLine, n)
+		for i := range lines {
+			lines[i] = numberedLine{no: i + 1, text: "line"}
+		}
+		return lines
+	}
+	oneTokenPerLine := func(text string) int { return strings.Count(text, "\n") }
+
+	tests := []struct {
+		name      string
+		lines     int
+		rule      Truncation
+		want      string // Line numbers kept, 0 for a marker
+		truncated bool
+	}{
+		{"none", 3, Truncation{}, "1 2 3", false},
+		{"head fits", 3, Truncation{TruncateHead, 3}, "1 2 3", false},
+		{"head", 5, Truncation{TruncateHead, 2}, "1 2 0", true},
+		{"tail", 5, Truncation{TruncateTail, 2}, "0 4 5", true},
+		{"headtail fits", 4, Truncation{TruncateHeadTail, 2}, "1 2 3 4", false},
+		{"headtail", 6, Truncation{TruncateHeadTail, 2}, "1 2 0 5 6", true},
+		{"tokens fits", 3, Truncation{TruncateTokens, 3}, "1 2 3", false},
+		{"tokens", 5, Truncation{TruncateTokens, 3}, "1 2 3 0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, truncated := truncateLines(numbered(tt.lines), tt.rule, oneTokenPerLine)
+			var kept []string
+			for _, line := range lines {
+				kept = append(kept, strconv.Itoa(line.no))
+			}
+			if got := strings.Join(kept, " "); got != tt.want || truncated != tt.truncated {
+				t.Errorf("kept %q (truncated %v), want %q (truncated %v)", got, truncated, tt.want, tt.truncated)
+			}
+		})
+	}
+}
+
+// TestTruncateTokensUneven checks the token cut when lines differ widely in
+// tokens per byte: the kept lines stay within the limit, and the text is
+// measured a few times rather than once per line.
+func TestTruncateTokensUneven(t *testing.T) {
+	var lines []numberedLine
+	for i := range 200 {
+		text := strings.Repeat(".", 40) // One token per 8 bytes
+		if i < 20 {
+			text = strings.Repeat("x", 40) // One token per byte
+		}
+		lines = append(lines, numberedLine{no: i + 1, text: text})
+	}
+	calls := 0
+	count := func(text string) int {
+		calls++
+		xs := strings.Count(text, "x")
+		return xs + (len(text)-xs)/8
+	}
+
+	rule := Truncation{TruncateTokens, 500}
+	kept, truncated := truncateLines(lines, rule, count)
+	if !truncated || kept[len(kept)-1].no != 0 {
+		t.Fatalf("not truncated: %d lines kept", len(kept))
+	}
+	kept = kept[:len(kept)-1] // The marker
+	if tokens := count(joinLines(kept)); tokens > rule.N {
+		t.Errorf("kept %d lines of %d tokens, over the limit %d", len(kept), tokens, rule.N)
+	}
+	if calls > 10 {
+		t.Errorf("the text was measured %d times", calls)
+	}
+}
+
+func TestTruncationMarkers(t *testing.T) {
+	app := newTestApp(t, map[string]string{
+		"long.go":  strings.Repeat("x\n", 10),
+		"short.go": "x\n",
+		"other.go": strings.Repeat("x\n", 10),
+	})
+	app.truncation = Truncation{TruncateHead, 5}
+	app.truncations["other.go"] = Truncation{TruncateTail, 2}
+	selected := map[string]bool{"long.go": true, "short.go": true}
+
+	markers := app.truncationMarkers(selected)
+	want := map[string]string{"long.go": "head 5", "other.go": "tail 2"}
+	if len(markers) != len(want) || markers["long.go"] != want["long.go"] || markers["other.go"] != want["other.go"] {
+		t.Fatalf("markers = %v, want %v", markers, want)
+	}
+
+	// Unchanged selection, rules and files: the files are not read again.
+	app.truncationCut = map[string]bool{"short.go": true} // Returned only if the cache is used
+	if markers := app.truncationMarkers(selected); markers["short.go"] != "head 5" || markers["long.go"] != "" {
+		t.Errorf("markers = %v, want the cached ones", markers)
+	}
+
+	// An edited file is checked again.
+	if err := os.WriteFile(filepath.Join(app.roots[0].Dir, "short.go"), []byte(strings.Repeat("x\n", 12)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if markers := app.truncationMarkers(selected); markers["short.go"] != "head 5" || markers["long.go"] != "head 5" {
+		t.Errorf("markers = %v after editing short.go", markers)
+	}
+
+	// A new rule is checked against the files.
+	app.truncation = Truncation{TruncateHead, 8}
+	if markers := app.truncationMarkers(selected); markers["short.go"] != "head 8" || markers["long.go"] != "head 8" {
+		t.Errorf("markers = %v after changing the rule", markers)
+	}
+}
@@ -2,8 +2,6 @@ package internal
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/awesome-gocui/gocui"
@@ -13,7 +11,18 @@ import (
 func (app *App) Layout(g *gocui.Gui) error {
 	app.mutex.Lock()
 	showCache := app.showCacheView
+	showSkipped := app.showSkippedView
 	showHelp := app.showHelp // Need help state for main layout too
+	showTransforms := app.showTransforms
+	showRefsPrompt := app.showRefsPrompt
+	showReferences := app.showReferences
+	showTemplates := app.showTemplates
+	showInstruction := app.showInstruction
+	showExportPrompt := app.showExportPrompt
+	showHistory := app.showHistory
+	showCopyPreview := app.showCopyPreview
+	showOrder := app.showOrder
+	showTruncPrompt := app.showTruncPrompt
 	isLoading := app.isLoading
 	loadingError := app.loadingError
 	app.mutex.Unlock()
@@ -30,16 +39,59 @@ func (app *App) Layout(g *gocui.Gui) error {
 
 	if showCache {
 		return app.layoutCacheView(g) // Cache view takes precedence
+	} else if showSkipped {
+		return app.layoutSkippedView(g)
 	} else if showHelp {
 		// Render main layout first, then overlay help
 		_ = app.GrepApplicationView(g)
 		return app.layoutHelpView(g) // Help view overlays main view
+	} else if showTransforms {
+		_ = g.DeleteView(HelpViewName)
+		_ = app.GrepApplicationView(g)
+		return app.layoutTransformsView(g)
+	} else if showRefsPrompt {
+		_ = app.GrepApplicationView(g)
+		return app.layoutRefsPromptView(g)
+	} else if showReferences {
+		_ = app.GrepApplicationView(g)
+		return app.layoutReferencesView(g)
+	} else if showTemplates {
+		_ = app.GrepApplicationView(g)
+		return app.layoutTemplatesView(g)
+	} else if showInstruction {
+		_ = app.GrepApplicationView(g)
+		return app.layoutInstructionView(g)
+	} else if showExportPrompt {
+		_ = app.GrepApplicationView(g)
+		return app.layoutExportPromptView(g)
+	} else if showHistory {
+		_ = app.GrepApplicationView(g)
+		return app.layoutHistoryView(g)
+	} else if showCopyPreview {
+		_ = app.GrepApplicationView(g)
+		return app.layoutCopyPreviewView(g)
+	} else if showOrder {
+		_ = app.GrepApplicationView(g)
+		return app.layoutOrderView(g)
+	} else if showTruncPrompt {
+		_ = app.GrepApplicationView(g)
+		return app.layoutTruncPromptView(g)
 	} else {
 		// Ensure help view is deleted if it exists and shouldn't be open
 		// This needs to happen *before* setting focus back in GrepApplicationView
 		if _, err := g.View(HelpViewName); err == nil {
 			_ = g.DeleteView(HelpViewName)
 		}
+		_ = g.DeleteView(TransformsViewName)
+		_ = g.DeleteView(RefsPromptViewName)
+		_ = g.DeleteView(ReferencesViewName)
+		_ = g.DeleteView(TemplatesViewName)
+		_ = g.DeleteView(InstructionViewName)
+		_ = g.DeleteView(ExportPromptViewName)
+		_ = g.DeleteView(HistoryViewName)
+		_ = g.DeleteView(CopyPreviewViewName)
+		_ = g.DeleteView(OrderViewName)
+		_ = g.DeleteView(TruncPromptViewName)
 		return app.GrepApplicationView(g) // Normal view
 	}
 }
@@ -50,28 +102,13 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 
 	// Ensure modal views (except help, handled in Layout) are gone
 	_ = g.DeleteView(CacheViewName)
+	_ = g.DeleteView(SkippedViewName)
 	_ = g.DeleteView("loading") // Ensure loading view is gone
 	_ = g.DeleteView("error")   // Ensure error view is gone
 
-	filesWidth := maxX / 3
-	pathHeight := 2
-	filterHeight := 3
-	statusBarHeight := 2 // Status bar takes 2 lines now
-
-	// --- Y coordinates ---
-	pathY0 := 0
-	pathY1 := pathY0 + pathHeight // Occupies lines 0, 1
-
-	filesY0 := pathY1 + 1                                // Starts at line 2
-	filesViewY1 := maxY - statusBarHeight - filterHeight // Ends before filter and status
-
-	filterY0 := filesViewY1 + 1             // Starts below files
-	filterY1 := filterY0 + filterHeight - 1 // Ends before status
-
-	contentViewY1 := maxY - statusBarHeight // Content ends before status bar
-
-	statusBarY0 := maxY - statusBarHeight // Status starts
-	statusBarY1 := maxY                   // Status ends at bottom
+	app.mutex.Lock()
+	panes := app.layout.panes(maxX, maxY, app.stackWidth)
+	app.mutex.Unlock()
 
 	currentView := g.CurrentView()
 	currentViewName := ""
@@ -80,40 +117,43 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 	}
 
 	// --- Path View ---
-	if pv, err := g.SetView(PathViewName, 0, pathY0, filesWidth, pathY1, 0); err != nil {
+	if pv, err := g.SetView(PathViewName, panes.path.x0, panes.path.y0, panes.path.x1, panes.path.y1, 0); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
 		pv.Title = " Directory "
+		if len(app.RootDirs()) > 1 {
+			pv.Title = " Directories "
+		}
 		pv.Editable = false
 		pv.Wrap = false
 		pv.Frame = true
-		pv.FrameColor = gocui.ColorBlue // Path view never focused
-		pv.FgColor = gocui.ColorMagenta
+		pv.FrameColor = app.theme.FrameBlurred // Path view never focused
+		pv.FgColor = app.theme.Path
 		pv.Clear() // Clear before writing
-		fmt.Fprint(pv, app.rootDir)
+		fmt.Fprint(pv, app.rootsDescription())
 	} else {
-		// Update content if needed (e.g., if rootDir could change - not currently possible)
+		// Update content if needed (e.g., if roots could change - not currently possible)
 		pv.Clear()
-		fmt.Fprint(pv, app.rootDir)
+		fmt.Fprint(pv, app.rootsDescription())
 	}
 
 	// --- Files View ---
-	if v, err := g.SetView(FilesViewName, 0, filesY0, filesWidth, filesViewY1, 0); err != nil {
+	if v, err := g.SetView(FilesViewName, panes.files.x0, panes.files.y0, panes.files.x1, panes.files.y1, 0); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
 		v.Title = " Files " // Title updated in refreshFilesView
 		v.Highlight = true
-		v.SelBgColor = gocui.ColorDefault               // Background for selected line
-		v.SelFgColor = gocui.ColorCyan | gocui.AttrBold // Foreground for selected line
+		v.SelBgColor = app.theme.CursorBg // Background for selected line
+		v.SelFgColor = app.theme.Cursor   // Foreground for selected line
 		v.Editable = false
 		v.Wrap = false
 		v.Autoscroll = false // We handle scrolling manually
 
 		// Set initial focus if needed (e.g., on startup or returning from cache)
 		// Help view focus is handled in Layout()
-		if g.CurrentView() == nil || currentViewName == CacheViewName {
+		if g.CurrentView() == nil || currentViewName == CacheViewName || currentViewName == SkippedViewName {
 			if _, err := g.SetCurrentView(FilesViewName); err != nil {
 				return err
 			}
@@ -125,14 +165,14 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 	fv, _ := g.View(FilesViewName)
 	if fv != nil { // Check if view exists before setting color
 		if currentViewName == FilesViewName {
-			fv.FrameColor = gocui.ColorGreen // Focused
+			fv.FrameColor = app.theme.FrameFocused // Focused
 		} else {
-			fv.FrameColor = gocui.ColorBlue // Not focused
+			fv.FrameColor = app.theme.FrameBlurred // Not focused
 		}
 	}
 
 	// --- Filter View ---
-	if v, err := g.SetView(FilterViewName, 0, filterY0, filesWidth, filterY1, 0); err != nil {
+	if v, err := g.SetView(FilterViewName, panes.filter.x0, panes.filter.y0, panes.filter.x1, panes.filter.y1, 0); err != nil {
 		if err != gocui.ErrUnknownView { // This means view was just created
 			return err
 		}
@@ -140,7 +180,7 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 		v.Editable = true // Will be refined by focus logic below
 		v.Wrap = false
 		v.Editor = gocui.DefaultEditor // Use default editor for input
-		v.FgColor = gocui.ColorCyan
+		v.FgColor = app.theme.Filter
 		// Title is set below based on mode
 	}
 
@@ -154,12 +194,12 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 
 	filterV, _ := g.View(FilterViewName) // Get the view
 	if filterV != nil {
-		filterV.Title = fmt.Sprintf(" Filter: %s (Ctrl+F: Mode) ", modeStr)
+		filterV.Title = fmt.Sprintf(" Filter: %s (%s: Mode) ", modeStr, app.keyHint(FilterViewName, "toggle_filter_mode"))
 		isFilterViewFocused := (currentViewName == FilterViewName)
 
 		if isFilterViewFocused {
-			filterV.FrameColor = gocui.ColorGreen
-			filterV.FgColor = gocui.ColorWhite | gocui.AttrBold
+			filterV.FrameColor = app.theme.FrameFocused
+			filterV.FgColor = app.theme.FilterEditing
 
 			if !filterV.Editable { // View is gaining focus AND was not previously editable
 				filterV.Editable = true
@@ -186,8 +226,8 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 				}
 			}
 		} else { // Filter view is NOT focused
-			filterV.FrameColor = gocui.ColorBlue
-			filterV.FgColor = gocui.ColorCyan
+			filterV.FrameColor = app.theme.FrameBlurred
+			filterV.FgColor = app.theme.Filter
 			if filterV.Editable {
 				filterV.Editable = false
 			}
@@ -196,9 +236,14 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 	}
 
 	// --- Content View ---
-	contentX0 := filesWidth + 1
-	contentY0 := 0 // Content view starts at the top now
-	if v, err := g.SetView(ContentViewName, contentX0, contentY0, maxX-1, contentViewY1, 0); err != nil {
+	if !panes.showContent {
+		if currentViewName == ContentViewName {
+			if _, err := g.SetCurrentView(FilesViewName); err != nil {
+				return err
+			}
+		}
+		_ = g.DeleteView(ContentViewName) // Hidden with toggle_preview
+	} else if v, err := g.SetView(ContentViewName, panes.content.x0, panes.content.y0, panes.content.x1, panes.content.y1, 0); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
@@ -210,34 +255,384 @@ func (app *App) GrepApplicationView(g *gocui.Gui) error {
 	}
 	// Always refresh content view and update frame color
 	app.refreshContentView(g) // Refreshes content and title
-	cv, _ := g.View(ContentViewName)
-	if cv != nil {
+	if cv, err := g.View(ContentViewName); err == nil {
 		if currentViewName == ContentViewName {
-			cv.FrameColor = gocui.ColorGreen // Focused
+			cv.FrameColor = app.theme.FrameFocused // Focused
 		} else {
-			cv.FrameColor = gocui.ColorBlue // Not focused
+			cv.FrameColor = app.theme.FrameBlurred // Not focused
 		}
 	}
 
 	// --- Status Bar ---
-	if v, err := g.SetView(StatusViewName, 0, statusBarY0, maxX-1, statusBarY1, 0); err != nil {
+	if v, err := g.SetView(StatusViewName, panes.status.x0, panes.status.y0, panes.status.x1, panes.status.y1, 0); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
 		v.Frame = false
 		v.Editable = false
 		v.Wrap = false
-		v.FgColor = gocui.ColorWhite
+		v.FgColor = app.theme.Text
 		v.BgColor = gocui.ColorDefault // Or maybe ColorBlue? Default is usually fine.
 	}
+
+	// --- Divider ---
+	if !panes.showDivider {
+		_ = g.DeleteView(DividerViewName)
+	} else if v, err := g.SetView(DividerViewName, panes.divider.x0, panes.divider.y0, panes.divider.x1, panes.divider.y1, 0); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Frame = false
+		v.Visible = false // Only catches clicks on the border, see PaneLayout.panes
+	}
+
 	// Always reset status unless awaiting cache confirmation
 	app.mutex.Lock()
 	awaitingConfirm := app.awaitingCacheClearConfirmation
 	app.mutex.Unlock()
 	if !awaitingConfirm {
-		app.resetStatus(g) // Update status bar text (NOW INCLUDES COUNTS)
+		app.renderStatus(g) // Update status bar text (NOW INCLUDES COUNTS)
+	}
+
+	return nil
+}
+
+// rootsDescription returns the text shown in the Path view: the root directory,
+// or "label=dir" pairs when several roots are open.
+func (app *App) rootsDescription() string {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if len(app.roots) == 1 {
+		return app.roots[0].Dir
+	}
+	parts := make([]string, len(app.roots))
+	for i, root := range app.roots {
+		parts[i] = fmt.Sprintf("%s=%s", root.Label, root.Dir)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// layoutTransformsView overlays the transform checklist on the main view and
+// focuses it.
+func (app *App) layoutTransformsView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width := min(maxX-2, 70)
+	height := len(transformOptions) + 5
+	x0, y0 := (maxX-width)/2, (maxY-height)/2
+
+	if v, err := g.SetView(TransformsViewName, x0, y0, x0+width-1, y0+height-1, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = fmt.Sprintf(" Transforms (1-%d: Toggle, %s: Close) ", len(transformOptions),
+			app.keyHints(TransformsViewName, "close", "transforms"))
+		v.Frame = true
+		v.FgColor = app.theme.Text
+		app.renderTransformsView(g)
+	}
+	if _, err := g.SetCurrentView(TransformsViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(TransformsViewName)
+	return nil
+}
+
+// layoutRefsPromptView overlays the one-line identifier prompt.
+func (app *App) layoutRefsPromptView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width := min(maxX-2, 60)
+	x0, y0 := (maxX-width)/2, maxY/2-1
+
+	if v, err := g.SetView(RefsPromptViewName, x0, y0, x0+width-1, y0+2, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = fmt.Sprintf(" Find references to (%s: Search, %s: Cancel) ",
+			app.keyHint(RefsPromptViewName, "confirm"), app.keyHint(RefsPromptViewName, "close"))
+		v.Editable = true
+		v.Editor = gocui.DefaultEditor
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+
+		app.mutex.Lock()
+		initial := app.refsPromptText
+		app.mutex.Unlock()
+		fmt.Fprint(v, initial)
+		_ = v.SetCursor(len(initial), 0)
+	}
+	if _, err := g.SetCurrentView(RefsPromptViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(RefsPromptViewName)
+	return nil
+}
+
+// layoutReferencesView overlays the files referencing the searched identifier.
+func (app *App) layoutReferencesView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width, height := maxX*2/3, maxY*2/3
+	x0, y0 := (maxX-width)/2, (maxY-height)/2
+
+	v, err := g.SetView(ReferencesViewName, x0, y0, x0+width-1, y0+height-1, gocui.TOP)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+		v.FgColor = app.theme.Text
+
+		app.mutex.Lock()
+		ident := app.refsIdent
+		hits := app.refsHits
+		app.mutex.Unlock()
+
+		total := 0
+		for _, hit := range hits {
+			total += hit.count
+		}
+		v.Title = fmt.Sprintf(" References to %s: %d in %d file(s) - %s: Select, %s: Cancel ", ident, total, len(hits),
+			app.keyHint(ReferencesViewName, "confirm"), app.keyHint(ReferencesViewName, "close"))
+		if len(hits) == 0 {
+			fmt.Fprintln(v, "\n  No references found.")
+		}
+		for _, hit := range hits {
+			var notes []string
+			if hit.declares {
+				notes = append(notes, "declares")
+			}
+			if hit.nameMatch {
+				notes = append(notes, "name match")
+			}
+			if hit.hidden {
+				notes = append(notes, "hidden by filter, not selected")
+			}
+			line := fmt.Sprintf("%5d  %s", hit.count, hit.path)
+			if len(notes) > 0 {
+				line += " (" + strings.Join(notes, ", ") + ")"
+			}
+			fmt.Fprintln(v, line)
+		}
+	}
+	if _, err := g.SetCurrentView(ReferencesViewName); err != nil {
+		return err
 	}
+	_, _ = g.SetViewOnTop(ReferencesViewName)
+	return nil
+}
+
+// layoutTemplatesView overlays the prompt template picker.
+func (app *App) layoutTemplatesView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width, height := min(maxX-2, 80), maxY/2
+	x0, y0 := (maxX-width)/2, (maxY-height)/2
 
+	if v, err := g.SetView(TemplatesViewName, x0, y0, x0+width-1, y0+height-1, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = fmt.Sprintf(" Copy with template (%s: Copy, %s: Cancel) ",
+			app.keyHint(TemplatesViewName, "confirm"), app.keyHint(TemplatesViewName, "close"))
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+		v.FgColor = app.theme.Text
+		v.Highlight = true
+		v.SelBgColor = app.theme.CursorBg
+		v.SelFgColor = app.theme.Cursor
+		app.renderTemplatesView(g)
+	}
+	if _, err := g.SetCurrentView(TemplatesViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(TemplatesViewName)
+	return nil
+}
+
+// layoutExportPromptView overlays the prompt for the export path.
+func (app *App) layoutExportPromptView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width := min(maxX-2, 100)
+	x0, y0 := (maxX-width)/2, maxY/2-1
+
+	if v, err := g.SetView(ExportPromptViewName, x0, y0, x0+width-1, y0+2, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = fmt.Sprintf(" Export bundle to (%s: Export, %s: Cancel) ",
+			app.keyHint(ExportPromptViewName, "confirm"), app.keyHint(ExportPromptViewName, "close"))
+		v.Editable = true
+		v.Editor = gocui.DefaultEditor
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+
+		app.mutex.Lock()
+		initial := app.exportPromptText
+		app.mutex.Unlock()
+		fmt.Fprint(v, initial)
+		_ = v.SetCursor(len(initial), 0)
+		if w, _ := v.Size(); len(initial) >= w {
+			_ = v.SetOrigin(len(initial)-w+1, 0)
+			_ = v.SetCursor(w-1, 0)
+		}
+	}
+	if _, err := g.SetCurrentView(ExportPromptViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(ExportPromptViewName)
+	return nil
+}
+
+// layoutTruncPromptView overlays the prompt for the truncation rule of one
+// file.
+func (app *App) layoutTruncPromptView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width := min(maxX-2, 100)
+	x0, y0 := (maxX-width)/2, maxY/2-1
+
+	if v, err := g.SetView(TruncPromptViewName, x0, y0, x0+width-1, y0+2, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		app.mutex.Lock()
+		relPath := app.truncPromptPath
+		t, overridden := app.truncations[relPath]
+		if !overridden {
+			t = app.truncation
+		}
+		app.mutex.Unlock()
+
+		v.Title = fmt.Sprintf(" Truncate %s (%s: Apply, %s: Cancel) ", relPath,
+			app.keyHint(TruncPromptViewName, "confirm"), app.keyHint(TruncPromptViewName, "close"))
+		v.Subtitle = " head:N tail:N headtail:N tokens:N none "
+		v.Editable = true
+		v.Editor = gocui.DefaultEditor
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+
+		initial := ""
+		if overridden || t.Active() {
+			initial = t.String()
+		}
+		fmt.Fprint(v, initial)
+		_ = v.SetCursor(len(initial), 0)
+	}
+	if _, err := g.SetCurrentView(TruncPromptViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(TruncPromptViewName)
+	return nil
+}
+
+// layoutHistoryView overlays the browser of exported bundles.
+func (app *App) layoutHistoryView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width, height := maxX-4, maxY-4
+	x0, y0 := (maxX-width)/2, (maxY-height)/2
+
+	if v, err := g.SetView(HistoryViewName, x0, y0, x0+width-1, y0+height-1, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+		v.FgColor = app.theme.Text
+		v.SelBgColor = app.theme.CursorBg
+		v.SelFgColor = app.theme.Cursor
+		v.Wrap = false
+		app.renderHistoryView(g) // Sets title and highlight for list or diff
+	}
+	if _, err := g.SetCurrentView(HistoryViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(HistoryViewName)
+	return nil
+}
+
+// layoutCopyPreviewView covers the screen, except the status bar, with the
+// bundle about to be copied.
+func (app *App) layoutCopyPreviewView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	statusBarHeight := 2
+
+	if v, err := g.SetView(CopyPreviewViewName, 0, 0, maxX-1, maxY-statusBarHeight, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		app.mutex.Lock()
+		text := app.previewText
+		v.Title = app.previewTitle
+		app.mutex.Unlock()
+
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+		v.Wrap = true // Like the Content view
+		fmt.Fprint(v, text)
+	}
+	if _, err := g.SetCurrentView(CopyPreviewViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(CopyPreviewViewName)
+	return nil
+}
+
+// layoutOrderView overlays the selected files in bundle order, with the
+// keys for reordering them in a footer line.
+func (app *App) layoutOrderView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width, height := min(maxX-2, 90), maxY*2/3
+	x0, y0 := (maxX-width)/2, (maxY-height)/2
+
+	if v, err := g.SetView(OrderViewName, x0, y0, x0+width-1, y0+height-1, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+		v.FgColor = app.theme.Text
+		v.Highlight = true
+		v.SelBgColor = app.theme.CursorBg
+		v.SelFgColor = app.theme.Cursor
+		v.Subtitle = fmt.Sprintf(" %s/%s: Move, %s: Strategy, %s: Reset, %s: Close ",
+			app.keyHint(OrderViewName, "move_down"), app.keyHint(OrderViewName, "move_up"),
+			app.keyHint(OrderViewName, "cycle_order"), app.keyHint(OrderViewName, "reset_order"),
+			app.keyHint(OrderViewName, "close"))
+		app.renderOrderView(g)
+	}
+	if _, err := g.SetCurrentView(OrderViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(OrderViewName)
+	return nil
+}
+
+// layoutInstructionView overlays the multi-line instruction editor.
+func (app *App) layoutInstructionView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	width, height := maxX*2/3, maxY/2
+	x0, y0 := (maxX-width)/2, (maxY-height)/2
+
+	if v, err := g.SetView(InstructionViewName, x0, y0, x0+width-1, y0+height-1, gocui.TOP); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = app.instructionViewTitle()
+		v.Editable = true
+		v.Editor = gocui.DefaultEditor // Enter inserts a newline
+		v.Wrap = true
+		v.Frame = true
+		v.FrameColor = app.theme.FrameFocused
+
+		app.mutex.Lock()
+		instruction := app.instruction
+		app.mutex.Unlock()
+		fmt.Fprint(v, instruction)
+		lines := strings.Split(instruction, "\n")
+		_ = v.SetCursor(len(lines[len(lines)-1]), len(lines)-1)
+	}
+	if _, err := g.SetCurrentView(InstructionViewName); err != nil {
+		return err
+	}
+	_, _ = g.SetViewOnTop(InstructionViewName)
 	return nil
 }
 
@@ -253,53 +648,21 @@ func (app *App) layoutHelpView(g *gocui.Gui) error {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
-		v.Title = " Help (? or Esc or q to Close) "
+		v.Title = fmt.Sprintf(" Help (%s: Scroll, %s: Close) ",
+			app.keyHints(HelpViewName, "cursor_down", "cursor_up"), app.keyHint(HelpViewName, "close"))
 		v.Wrap = true
 		v.Editable = false
 		v.Frame = true
-		v.FgColor = gocui.ColorWhite // Text color for help
+		v.FgColor = app.theme.Text // Text color for help
 
-		// --- Updated Help Text ---
 		v.Clear()
-		fmt.Fprintln(v, "grepforllm - Select & Copy File Contents")
-		fmt.Fprintln(v, "----------------------------------------")
-		fmt.Fprintln(v, "General:")
-		fmt.Fprintln(v, "  Tab           : Switch focus Files <-> Filter <-> Content")
-		fmt.Fprintln(v, "  ?             : Toggle this help message")
-		fmt.Fprintln(v, "  Ctrl+C        : Show Cache View")
-		fmt.Fprintln(v, "  q             : Quit / Close Help / Close Cache")
-		fmt.Fprintln(v, "  Ctrl+Q        : Force Quit Application")
-		fmt.Fprintln(v, "\nFiles View (Left):")
-		fmt.Fprintln(v, "  ↑ / k         : Move cursor up")
-		fmt.Fprintln(v, "  ↓ / j         : Move cursor down")
-		fmt.Fprintln(v, "  Enter         : Focus Content View for scrolling")
-		fmt.Fprintln(v, "  Space         : Toggle select file under cursor")
-		fmt.Fprintln(v, "  a             : Select / Deselect all visible files")
-		fmt.Fprintln(v, "  c / y         : Copy contents of selected files to clipboard")
-		fmt.Fprintln(v, "\nContent View (Right):")
-		fmt.Fprintln(v, "  ↑ / k         : Scroll content UP one line (when focused)")
-		fmt.Fprintln(v, "  ↓ / j         : Scroll content DOWN one line (when focused)")
-		fmt.Fprintln(v, "  PgUp / Ctrl+B : Scroll content UP one page (works globally)")
-		fmt.Fprintln(v, "  PgDn          : Scroll content DOWN one page (works globally)")
-		// fmt.Fprintln(v, "  Esc           : Return focus to Files View (Optional - Not bound by default)")
-		fmt.Fprintln(v, "\nFilter View (Bottom-Left):")
-		fmt.Fprintln(v, "  (Type patterns: *.go, cmd/, file.txt)")
-		fmt.Fprintln(v, "  Enter         : Apply filter & return focus to Files")
-		fmt.Fprintln(v, "  Esc           : Cancel input & return focus to Files")
-		fmt.Fprintln(v, "  Ctrl+F        : Toggle filter mode (Include/Exclude)")
-		fmt.Fprintln(v, "\nCache View (Ctrl+C):")
-		fmt.Fprintln(v, "  ↑ / k / ↓ / j : Scroll Line")
-		fmt.Fprintln(v, "  PgUp / PgDn   : Scroll Page")
-		fmt.Fprintln(v, "  Ctrl+D        : Prompt to clear cache")
-		fmt.Fprintln(v, "  y / n         : Confirm / Cancel cache clear")
-		fmt.Fprintln(v, "  Esc / q       : Close Cache View")
-		// --- End Updated Help Text ---
+		app.writeHelp(v) // Generated from the active keymap
 
 		// Set focus to Help view when it's created
 		if _, err := g.SetCurrentView(HelpViewName); err != nil {
 			return err
 		}
-		v.FrameColor = gocui.ColorGreen // Focused color
+		v.FrameColor = app.theme.FrameFocused // Focused color
 	} else {
 		// If view exists, ensure it's focused and has the right color
 		if g.CurrentView() != v {
@@ -307,7 +670,7 @@ func (app *App) layoutHelpView(g *gocui.Gui) error {
 				return err
 			}
 		}
-		v.FrameColor = gocui.ColorGreen // Focused color
+		v.FrameColor = app.theme.FrameFocused // Focused color
 	}
 	return nil
 }
@@ -319,7 +682,7 @@ func (app *App) layoutCacheView(g *gocui.Gui) error {
 
 	// --- Delete normal views ---
 	viewsToDelete := []string{
-		FilesViewName, ContentViewName, FilterViewName, PathViewName,
+		FilesViewName, ContentViewName, FilterViewName, PathViewName, DividerViewName,
 		HelpViewName, // Also delete help if it was open
 		"loading",    // Also delete loading/error views
 		"error",
@@ -337,16 +700,18 @@ func (app *App) layoutCacheView(g *gocui.Gui) error {
 	awaitingConfirm := app.awaitingCacheClearConfirmation
 	app.mutex.Unlock()
 
+	cacheViewCreated := false
 	if cv, err := g.SetView(CacheViewName, 0, 0, maxX-1, cacheViewY1, gocui.TOP); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
+		cacheViewCreated = true
 		cv.Title = " Cache Contents (cache.json) "
 		cv.Editable = false
 		cv.Wrap = true
 		cv.Autoscroll = false
 		cv.Frame = true
-		cv.FgColor = gocui.ColorWhite
+		cv.FgColor = app.theme.Text
 
 		cv.Clear()
 		fmt.Fprint(cv, cacheContent)
@@ -355,20 +720,20 @@ func (app *App) layoutCacheView(g *gocui.Gui) error {
 		if _, err := g.SetCurrentView(CacheViewName); err != nil {
 			return err
 		}
-		cv.FrameColor = gocui.ColorGreen
+		cv.FrameColor = app.theme.FrameFocused
 	} else {
 		cv.Clear()
 		fmt.Fprint(cv, cacheContent)
 		_ = cv.SetOrigin(0, cacheOriginY)
 
 		if g.CurrentView() == cv {
-			cv.FrameColor = gocui.ColorGreen
+			cv.FrameColor = app.theme.FrameFocused
 		} else {
 			// This case shouldn't happen if focus logic is correct
 			if _, err := g.SetCurrentView(CacheViewName); err == nil {
-				cv.FrameColor = gocui.ColorGreen
+				cv.FrameColor = app.theme.FrameFocused
 			} else {
-				cv.FrameColor = gocui.ColorCyan
+				cv.FrameColor = app.theme.FrameBlurred
 			}
 		}
 	}
@@ -384,19 +749,100 @@ func (app *App) layoutCacheView(g *gocui.Gui) error {
 		sv.Frame = false
 		sv.Editable = false
 		sv.Wrap = false
-		sv.FgColor = gocui.ColorWhite
+		sv.FgColor = app.theme.Text
 		sv.BgColor = gocui.ColorDefault
-		// Set initial status for cache view
-		if !awaitingConfirm {
-			app.resetStatusForCacheView(g)
-		} // else: PromptClearCache will set the status
-	} else {
-		// Update status if not awaiting confirmation
-		if !awaitingConfirm {
-			app.resetStatusForCacheView(g)
+	}
+	// Set initial status when the cache view opens (the status view is shared
+	// with the file browser, so it usually exists already).
+	if cacheViewCreated && !awaitingConfirm {
+		app.resetStatusForCacheView(g)
+	} // else: PromptClearCache will set the status
+
+	return nil
+}
+
+// layoutSkippedView renders the skipped files view, grouped by reason.
+func (app *App) layoutSkippedView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	// --- Delete normal views ---
+	viewsToDelete := []string{
+		FilesViewName, ContentViewName, FilterViewName, PathViewName, DividerViewName,
+		HelpViewName, CacheViewName,
+		"loading",
+		"error",
+	}
+	for _, viewName := range viewsToDelete {
+		_ = g.DeleteView(viewName) // Ignore ErrUnknownView
+	}
+
+	// --- Skipped View ---
+	skippedViewY1 := maxY - 2 // Leave space for status bar
+
+	app.mutex.Lock()
+	lines := app.skippedViewLines
+	cursor := app.skippedViewCursor
+	skippedCount := len(app.skippedFiles) + len(app.filterSkipped)
+	app.mutex.Unlock()
+
+	skippedViewCreated := false
+	sv, err := g.SetView(SkippedViewName, 0, 0, maxX-1, skippedViewY1, gocui.TOP)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		skippedViewCreated = true
+		sv.Editable = false
+		sv.Wrap = false
+		sv.Autoscroll = false
+		sv.Frame = true
+		sv.Highlight = true
+		sv.SelBgColor = app.theme.CursorBg
+		sv.SelFgColor = app.theme.Cursor
+		sv.FgColor = app.theme.Text
+		sv.FrameColor = app.theme.FrameFocused
+	}
+	sv.Title = fmt.Sprintf(" Skipped Files (%d) - %s: Force-include file, list directory ", skippedCount, app.keyHint(SkippedViewName, "toggle_select"))
+	sv.Clear()
+	for _, line := range lines {
+		fmt.Fprintln(sv, line.text)
+	}
+
+	// Keep the cursor line visible
+	_, vy := sv.Size()
+	_, oy := sv.Origin()
+	if cursor < oy {
+		oy = cursor
+	} else if cursor >= oy+vy {
+		oy = cursor - vy + 1
+	}
+	_ = sv.SetOrigin(0, max(0, oy))
+	_ = sv.SetCursor(0, cursor-max(0, oy))
+
+	if g.CurrentView() != sv {
+		if _, err := g.SetCurrentView(SkippedViewName); err != nil {
+			return err
 		}
 	}
 
+	// --- Status Bar ---
+	statusBarY0 := maxY - 2
+	statusBarY1 := maxY
+
+	if st, err := g.SetView(StatusViewName, 0, statusBarY0, maxX-1, statusBarY1, 0); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		st.Frame = false
+		st.Editable = false
+		st.Wrap = false
+		st.FgColor = app.theme.Text
+		st.BgColor = gocui.ColorDefault
+	}
+	if skippedViewCreated {
+		app.resetStatusForSkippedView(g)
+	}
+
 	return nil
 }
 
@@ -433,28 +879,56 @@ func (app *App) refreshFilesView(g *gocui.Gui) {
 	}
 	currentLine := app.currentLine
 	isCopyHighlightActive := app.isCopyHighlightActive
+	partial := make(map[string]bool, len(app.lineRanges))
+	for k := range app.lineRanges {
+		partial[k] = true
+	}
+	symlinked := make(map[string]bool, len(app.symlinkedFiles))
+	for k := range app.symlinkedFiles {
+		symlinked[k] = true
+	}
+	skeleton := make(map[string]bool, len(app.renderModes))
+	for k, mode := range app.renderModes {
+		skeleton[k] = mode == RenderSkeleton
+	}
 	app.mutex.Unlock()
 
-	title := fmt.Sprintf(" Files (%d/%d Sel) %s [?] Help ", selectedCount, totalCount, modeStr)
+	truncated := app.truncationMarkers(currentSelectedFiles)
+
+	title := fmt.Sprintf(" Files (%d/%d Sel) %s [%s] Help ", selectedCount, totalCount, modeStr, app.keyHint("", "toggle_help"))
 	v.Title = title
 
 	for i, file := range currentFileList {
 		isSelected := currentSelectedFiles[file]
 		isCurrent := (i == currentLine)
 		prefix := "[ ]"
-		if isSelected {
+		if isSelected && partial[file] {
+			prefix = "[~]" // Only marked line ranges are bundled
+		} else if isSelected {
 			prefix = "[*]"
 		}
 		line := fmt.Sprintf("%s %s", prefix, file)
+		if symlinked[file] {
+			line += "@" // Reached through a symlink, like ls -F
+		}
+		if skeleton[file] {
+			line += " {...}" // Bundled as a Go skeleton
+		}
+		if marker, ok := truncated[file]; ok {
+			line += " [" + marker + "]" // Cut down by a truncation rule
+		}
+		if isSensitivePath(file) {
+			line += " [!]" // Likely secrets, selecting needs confirmation
+		}
 
 		switch {
 		case isCopyHighlightActive && isSelected:
-			fmt.Fprintf(v, "\x1b[7m%s\x1b[0m\n", line) // Inverse video for copy highlight
+			fmt.Fprintln(v, paint(app.theme.CopyFlash, line)) // Flash the files just copied
 		case isCurrent:
 			// Let gocui handle highlighting the current line via SelFgColor/SelBgColor
 			fmt.Fprintln(v, line)
 		case isSelected:
-			fmt.Fprintf(v, "\x1b[32m%s\x1b[0m\n", line) // Green text for selected (not current)
+			fmt.Fprintln(v, paint(app.theme.Selected, line)) // Selected (not current)
 		default:
 			fmt.Fprintln(v, line)
 		}
@@ -469,7 +943,7 @@ func (app *App) refreshFilesView(g *gocui.Gui) {
 	app.adjustFilesViewScroll(g, v) // Ensure cursor is visible
 
 	// Refresh status bar whenever files view is refreshed, as selection count might change
-	app.resetStatus(g)
+	app.renderStatus(g)
 }
 
 // refreshContentView updates the content view with the file under the cursor.
@@ -487,7 +961,18 @@ func (app *App) refreshContentView(g *gocui.Gui) {
 	if fileListLen > 0 && currentLine >= 0 && currentLine < fileListLen {
 		fileToPreviewRelPath = app.fileList[currentLine]
 	}
-	rootDir := app.rootDir
+	linkTarget, isSymlinked := app.symlinkedFiles[fileToPreviewRelPath]
+	ranges := append([]LineRange(nil), app.lineRanges[fileToPreviewRelPath]...)
+	visualActive := app.visualActive
+	lineNumbers := app.lineNumbers
+	highlighter := app.highlighter
+	var transforms Transforms
+	previewSkeleton := false
+	if app.previewTransformed {
+		transforms = app.transforms
+		previewSkeleton = app.renderModes[fileToPreviewRelPath] == RenderSkeleton
+	}
+	visualStart, visualEnd := min(app.visualAnchor, app.visualCursor), max(app.visualAnchor, app.visualCursor)
 	previousPreviewedFile := app.currentlyPreviewedFile
 	currentContentOriginY := app.contentViewOriginY
 	app.mutex.Unlock()
@@ -499,16 +984,18 @@ func (app *App) refreshContentView(g *gocui.Gui) {
 	}
 
 	v.Clear()
+	// Marking a range needs one view line per file line.
+	v.Wrap = !visualActive
 
 	if fileToPreviewRelPath == "" {
 		v.Title = " Content - PgUp/PgDn Scroll "
 		fmt.Fprintln(v, "\nNo file selected or list is empty.")
-		fmt.Fprintln(v, "\nUse ↑ / ↓ to navigate files.")
-		fmt.Fprintln(v, "[Enter]   : Focus this view for scrolling (j/k)")
-		fmt.Fprintln(v, "[Space]   : Toggle select file")
-		fmt.Fprintln(v, "[c] / [y] : Copy selected files")
-		fmt.Fprintln(v, "[Tab]     : Switch focus")
-		fmt.Fprintln(v, "[?]       : Help")
+		fmt.Fprintf(v, "\nUse %s to navigate files.\n", app.keyHints(FilesViewName, "cursor_up", "cursor_down"))
+		fmt.Fprintf(v, "%-9s : Focus this view for scrolling\n", app.keyHint(FilesViewName, "focus_content"))
+		fmt.Fprintf(v, "%-9s : Toggle select file\n", app.keyHint(FilesViewName, "toggle_select"))
+		fmt.Fprintf(v, "%-9s : Copy selected files\n", app.keyHint(FilesViewName, "copy"))
+		fmt.Fprintf(v, "%-9s : Switch focus\n", app.keyHint("", "switch_focus"))
+		fmt.Fprintf(v, "%-9s : Help\n", app.keyHint("", "toggle_help"))
 		_ = v.SetOrigin(0, 0)
 		app.mutex.Lock()
 		app.currentlyPreviewedFile = ""
@@ -517,10 +1004,29 @@ func (app *App) refreshContentView(g *gocui.Gui) {
 		return
 	}
 
-	fullPath := filepath.Join(rootDir, fileToPreviewRelPath)
-	fileContentBytes, readErr := os.ReadFile(fullPath)
+	fileContentBytes, readErr := app.readFileContent(fileToPreviewRelPath)
+	if previewSkeleton && readErr == nil {
+		if skeleton, err := goSkeleton(fileToPreviewRelPath, fileContentBytes); err == nil {
+			// Line numbers and ranges refer to the full file, as in the bundle.
+			fileContentBytes = []byte(skeleton)
+			ranges, lineNumbers = nil, false
+		} else {
+			previewSkeleton = false
+		}
+	}
 
 	v.Title = fmt.Sprintf(" Content: %s - PgUp/PgDn Scroll ", fileToPreviewRelPath)
+	if isSymlinked {
+		v.Title = fmt.Sprintf(" Content: %s (via symlink -> %s) - PgUp/PgDn Scroll ", fileToPreviewRelPath, linkTarget)
+	}
+	if visualActive {
+		v.Title = fmt.Sprintf(" Content: %s - VISUAL lines %d-%d (%s: Mark, %s: Cancel) ", fileToPreviewRelPath, visualStart+1, visualEnd+1,
+			app.keyHint(ContentViewName, "mark_lines"), app.keyHint(ContentViewName, "close"))
+	} else if previewSkeleton {
+		v.Title = fmt.Sprintf(" Content: %s [skeleton, transforms: %s] - p: Raw ", fileToPreviewRelPath, transforms)
+	} else if transforms.Any() {
+		v.Title = fmt.Sprintf(" Content: %s [transformed: %s] - p: Raw ", fileToPreviewRelPath, transforms)
+	}
 
 	if readErr != nil {
 		fmt.Fprintf(v, "\n!!! ERROR READING FILE: %v !!!\n", readErr)
@@ -528,8 +1034,39 @@ func (app *App) refreshContentView(g *gocui.Gui) {
 		fmt.Fprintln(v, "(Empty File)")
 	} else if !isLikelyText(fileContentBytes) {
 		fmt.Fprintf(v, "(Binary File: %s)", fileToPreviewRelPath)
+	} else if len(ranges) == 0 && !visualActive && !lineNumbers && !transforms.Any() {
+		if colored := highlighter.Lines(fileToPreviewRelPath, string(fileContentBytes)); colored != nil {
+			fmt.Fprintln(v, strings.Join(colored, "\n"))
+		} else {
+			fmt.Fprint(v, string(fileContentBytes))
+		}
 	} else {
-		fmt.Fprint(v, string(fileContentBytes))
+		// Highlight marked ranges (green) and the range being marked (inverse),
+		// other lines by syntax.
+		width := gutterWidth(len(splitLines(string(fileContentBytes))))
+		lines := transformLines(fileToPreviewRelPath, string(fileContentBytes), transforms)
+		texts := make([]string, len(lines))
+		for i, numbered := range lines {
+			texts[i] = numbered.text
+		}
+		colored := highlighter.Lines(fileToPreviewRelPath, strings.Join(texts, "\n"))
+		for i, numbered := range lines {
+			lineNo, line := numbered.no, numbered.text
+			gutter := ""
+			if lineNumbers {
+				gutter = fmt.Sprintf("%*d | ", width, lineNo) // Same gutter as the bundle
+			}
+			switch {
+			case visualActive && lineNo-1 >= visualStart && lineNo-1 <= visualEnd:
+				fmt.Fprintln(v, paint(app.theme.Visual, gutter+line))
+			case lineInRanges(lineNo, ranges):
+				fmt.Fprintln(v, paint(app.theme.Marked, gutter+line))
+			case i < len(colored):
+				fmt.Fprintln(v, gutter+colored[i])
+			default:
+				fmt.Fprintln(v, gutter+line)
+			}
+		}
 	}
 
 	app.mutex.Lock()
@@ -570,7 +1107,13 @@ func (app *App) refreshContentView(g *gocui.Gui) {
 
 // --- Status Bar Functions ---
 
+// updateStatus shows message in the status bar. In the file browser it stays
+// visible across layouts until resetStatus is called.
 func (app *App) updateStatus(g *gocui.Gui, message string) {
+	app.mutex.Lock()
+	app.statusMessage = message
+	app.mutex.Unlock()
+
 	g.Update(func(g *gocui.Gui) error {
 		v, err := g.View(StatusViewName)
 		if err == nil {
@@ -584,88 +1127,116 @@ func (app *App) updateStatus(g *gocui.Gui, message string) {
 	})
 }
 
-// resetStatus sets the default status bar text for the normal file browser view.
+// resetStatus clears any status message and restores the default status bar
+// text for the normal file browser view.
 func (app *App) resetStatus(g *gocui.Gui) {
+	app.mutex.Lock()
+	app.statusMessage = ""
+	app.mutex.Unlock()
+
 	g.Update(func(g *gocui.Gui) error {
-		v, err := g.View(StatusViewName)
-		if err != nil && err != gocui.ErrUnknownView {
-			return err // Return actual error if not ErrUnknownView
-		}
-		if err == gocui.ErrUnknownView {
-			return nil // View doesn't exist yet, nothing to do
-		}
+		app.renderStatus(g)
+		return nil
+	})
+}
 
-		// --- Calculate Character and Token Counts ---
-		app.mutex.Lock()
-		// Copy needed state under lock
-		selectedFilesCopy := make(map[string]bool, len(app.selectedFiles))
-		for k, v := range app.selectedFiles {
-			selectedFilesCopy[k] = v
-		}
-		rootDirCopy := app.rootDir
-		tokenizer := app.tokenizer // Assuming tokenizer is thread-safe or immutable after init
-		app.mutex.Unlock()
+// renderStatus draws the file browser status bar: the pending status message if
+// there is one, otherwise character and token counts for the selection.
+// Must run on the GUI thread (it is called directly from Layout).
+func (app *App) renderStatus(g *gocui.Gui) {
+	v, err := g.View(StatusViewName)
+	if err != nil {
+		return // View doesn't exist yet, nothing to do
+	}
+
+	app.mutex.Lock()
+	message := app.statusMessage
+	app.mutex.Unlock()
+	if message != "" {
+		v.Clear()
+		fmt.Fprint(v, message)
+		v.Rewind()
+		return
+	}
 
-		totalChars := 0
-		totalTokens := 0
-		readErrors := 0
+	// --- Calculate Character and Token Counts ---
+	app.mutex.Lock()
+	// Copy needed state under lock
+	selectedPaths := make([]string, 0, len(app.selectedFiles))
+	for k := range app.selectedFiles {
+		selectedPaths = append(selectedPaths, k)
+	}
+	tokenizer := app.tokenizer // Assuming tokenizer is thread-safe or immutable after init
+	instruction := app.instruction
+	app.dropStaleBundleParts()
+	part, partCount := app.bundlePart, len(app.bundleParts)
+	app.mutex.Unlock()
 
-		if tokenizer == nil {
-			// Handle case where tokenizer might not be initialized (shouldn't happen)
-			fmt.Fprintf(os.Stderr, "Warning: Tokenizer not initialized in resetStatus\n")
-		} else {
-			for relPath := range selectedFilesCopy {
-				fullPath := filepath.Join(rootDirCopy, relPath)
-				contentBytes, readErr := os.ReadFile(fullPath)
-				if readErr != nil {
-					// Log error or just count them? Let's count for now.
-					// log.Printf("Warning: Failed to read file %s for status count: %v", fullPath, readErr)
-					readErrors++
-					continue // Skip this file
-				}
+	partStr := ""
+	if partCount > 0 {
+		partStr = fmt.Sprintf(" | Part %d/%d on clipboard (%s: next)", part+1, partCount, app.keyHint(FilesViewName, "copy_next_part"))
+	}
 
-				// Count characters (bytes)
-				totalChars += len(contentBytes)
-
-				// Count tokens
-				// Use Encode with suppress_special_tokens=True, allowed_special="all" equivalent if needed
-				// For basic counting, default Encode is usually fine.
-				tokens := tokenizer.Encode(string(contentBytes), nil, nil)
-				if err != nil {
-					// Log tokenizer error?
-					// log.Printf("Warning: Failed to tokenize file %s: %v", fullPath, err)
-					readErrors++ // Count as error if tokenization fails
-					continue
-				}
-				totalTokens += len(tokens)
-			}
+	totalChars, totalTokens, readErrors := app.bundleSize(selectedPaths)
+	if instruction != "" {
+		totalChars += len(instruction)
+		if tokenizer != nil {
+			totalTokens += len(tokenizer.Encode(instruction, nil, nil))
 		}
-		// --- End Calculation ---
+	}
+	// --- End Calculation ---
 
-		v.Clear()
-		// Format the status string with counts and keybindings
-		statusFormat := "Chars: %d | Tokens: %d%s || ?: Help | q: Quit"
-		errorStr := ""
-		if readErrors > 0 {
-			errorStr = fmt.Sprintf(" (%d read err)", readErrors)
-		}
-		statusText := fmt.Sprintf(statusFormat, totalChars, totalTokens, errorStr)
+	v.Clear()
+	// Format the status string with counts and keybindings
+	tokensStr := fmt.Sprintf("%d", totalTokens)
+	if tokenizer == nil {
+		tokensStr = "n/a" // Encoding could not be loaded (e.g. offline on first run)
+	}
+	errorStr := ""
+	if readErrors > 0 {
+		errorStr = fmt.Sprintf(" (%d read err)", readErrors)
+	}
+	statusText := fmt.Sprintf("Chars: %d | Tokens: %s%s%s || %s: Help | %s: Quit", totalChars, tokensStr, errorStr, partStr,
+		app.keyHint("", "toggle_help"), app.keyHint("", "quit"))
 
-		fmt.Fprint(v, statusText)
-		v.Rewind()
+	fmt.Fprint(v, statusText)
+	v.Rewind()
+}
+
+// resetStatusForCacheView sets the default status bar text for the cache view.
+func (app *App) resetStatusForCacheView(g *gocui.Gui) {
+	app.mutex.Lock()
+	app.statusMessage = ""
+	app.mutex.Unlock()
 
+	g.Update(func(g *gocui.Gui) error {
+		v, err := g.View(StatusViewName)
+		if err == nil {
+			v.Clear()
+			fmt.Fprintf(v, "%s: Scroll | %s: Clear Cache | %s: Close Cache View",
+				app.keyHints(CacheViewName, "cursor_up", "cursor_down", "page_up", "page_down"),
+				app.keyHint(CacheViewName, "clear_cache"), app.keyHint(CacheViewName, "close"))
+			v.Rewind()
+		} else if err != gocui.ErrUnknownView {
+			return err
+		}
 		return nil
 	})
 }
 
-// resetStatusForCacheView sets the default status bar text for the cache view.
-func (app *App) resetStatusForCacheView(g *gocui.Gui) {
-	// This function remains the same
+// resetStatusForSkippedView sets the default status bar text for the skipped files view.
+func (app *App) resetStatusForSkippedView(g *gocui.Gui) {
+	app.mutex.Lock()
+	app.statusMessage = ""
+	app.mutex.Unlock()
+
 	g.Update(func(g *gocui.Gui) error {
 		v, err := g.View(StatusViewName)
 		if err == nil {
 			v.Clear()
-			fmt.Fprint(v, "↑↓ PgUp/Dn: Scroll | Ctrl+D: Clear Cache | Esc/q: Close Cache View")
+			fmt.Fprintf(v, "%s: Move | %s: Force-include file, list directory | %s: Close Skipped View",
+				app.keyHints(SkippedViewName, "cursor_up", "cursor_down", "page_up", "page_down"),
+				app.keyHints(SkippedViewName, "toggle_select", "confirm"), app.keyHint(SkippedViewName, "close"))
 			v.Rewind()
 		} else if err != gocui.ErrUnknownView {
 			return err
@@ -698,7 +1269,7 @@ func (app *App) layoutLoadingView(g *gocui.Gui) error {
 		}
 		v.Frame = false
 		v.Editable = false
-		v.FgColor = gocui.ColorCyan
+		v.FgColor = app.theme.Loading
 		fmt.Fprint(v, msg)
 	}
 	return nil
@@ -710,7 +1281,7 @@ func (app *App) layoutErrorView(g *gocui.Gui, loadErr error) error {
 		"Error during file scan:",
 		loadErr.Error(),
 		"",
-		"Press Ctrl+Q to quit.",
+		fmt.Sprintf("Press %s to quit.", app.keyHint("", "force_quit")),
 	}
 	maxWidth := 0
 	for _, line := range msgLines {
@@ -732,7 +1303,7 @@ func (app *App) layoutErrorView(g *gocui.Gui, loadErr error) error {
 		v.Frame = true
 		v.Editable = false
 		v.Wrap = true
-		v.FgColor = gocui.ColorRed
+		v.FgColor = app.theme.Error
 
 		v.Clear()
 		for _, line := range msgLines {
@@ -6,12 +6,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // --- Cache Helper Functions ---
 
 // getCacheFilePath determines the path for the cache file.
 func getCacheFilePath() (string, error) {
+	cacheDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "cache.json"), nil // ~/.config/grepforllm/cache.json
+}
+
+// getConfigDir returns (and creates) the grepforllm config directory, which
+// holds the cache and user templates.
+func getConfigDir() (string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user config directory: %w", err)
@@ -22,8 +33,7 @@ func getCacheFilePath() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to create cache directory %s: %w", cacheDir, err)
 	}
-
-	return filepath.Join(cacheDir, "cache.json"), nil // ~/.config/grepforllm/cache.json
+	return cacheDir, nil
 }
 
 // loadCache reads the cache file and unmarshals it.
@@ -105,6 +115,29 @@ func isLikelyText(data []byte) bool {
 	return true
 }
 
+// insertSorted inserts value into the sorted slice if it is not already present.
+func insertSorted(values []string, value string) []string {
+	i := sort.SearchStrings(values, value)
+	if i < len(values) && values[i] == value {
+		return values
+	}
+	values = append(values, "")
+	copy(values[i+1:], values[i:])
+	values[i] = value
+	return values
+}
+
+// removeString returns values without any occurrence of value.
+func removeString(values []string, value string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -6,57 +6,277 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/adimail/grepforllm/internal"
 	"github.com/awesome-gocui/gocui"
 )
 
+// dirList collects repeated --dir flags.
+type dirList []string
+
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(value string) error {
+	*d = append(*d, value)
+	return nil
+}
+
 func main() {
 	// --- Argument Parsing ---
-	rootDir := flag.String("dir", ".", "Root directory to scan")
+	var rootDirs dirList
+	flag.Var(&rootDirs, "dir", "Root directory to scan (repeatable; positional paths are also accepted, default \".\")")
+	symlinks := flag.String("symlinks", "", "Symlink policy: skip, list or follow (default: last used for this directory, else follow)")
+	symlinkEscape := flag.Bool("symlink-escape", false, "Allow followed symlinks to point outside the root directory")
+	selectFrom := flag.String("select-from", "", "Pre-select paths listed in a file, or - for stdin (one path per line)")
+	lineNumbers := flag.Bool("line-numbers", false, "Prefix every bundled line with its line number; =false turns it off (overrides the cached setting for this run)")
+	transforms := flag.String("transforms", "", "Content transforms: comma-separated comments, blank, trailing, license, all or none (overrides the cached setting for this run)")
+	order := flag.String("order", "", "Bundle order of files not moved by hand: alphabetical, dependency, mtime or tests-last (overrides the cached setting for this run)")
+	importDepth := flag.Int("import-depth", internal.DefaultImportDepth, "Levels of in-module Go imports followed by G in the Files view")
+	templateName := flag.String("template", "", "With --headless, render the bundle through this prompt template (file name without .tmpl)")
+	clipboardBackend := flag.String("clipboard", "", "Preferred clipboard backend: auto, native, osc52, tmux, command or file (default: \"clipboard\" in config.json, else auto)")
+	clipboardFile := flag.String("clipboard-file", "", "File written by the file clipboard backend (default: \"clipboardFile\" in config.json, else grepforllm-bundle.md in $XDG_RUNTIME_DIR or the user cache directory)")
+	clipboardCommand := flag.String("clipboard-command", "", "Shell command the command clipboard backend pipes the bundle to (default: \"clipboardCommand\" in config.json)")
+	splitTokens := flag.Int("split-tokens", 0, "Copy bundles larger than this many tokens in labelled parts (default: \"splitTokens\" in config.json, else no limit)")
+	splitBytes := flag.Int("split-bytes", 0, "Copy bundles larger than this many bytes in labelled parts (default: \"splitBytes\" in config.json, else no limit)")
+	theme := flag.String("theme", "", "Colour theme: default, light-terminal, high-contrast, monochrome or one defined in config.json (default: \"theme\" in config.json, else default; NO_COLOR forces monochrome)")
+	syntaxTheme := flag.String("syntax-theme", "", "Chroma style highlighting the Content view, or none (default: \"syntaxTheme\" in config.json, else "+internal.DefaultSyntaxTheme+")")
+	truncate := flag.String("truncate", "", "Truncate bundled files: head:N, tail:N, headtail:N, tokens:N or none (default: \"truncate\" in config.json, else none)")
+	noMouse := flag.Bool("no-mouse", false, "Leave the mouse to the terminal, e.g. to select text (default: \"noMouse\" in config.json, else the mouse clicks, scrolls and resizes)")
+	headless := flag.Bool("headless", false, "Print the bundle to stdout instead of starting the TUI (bundles all visible files except .env*/*.pem if nothing is selected)")
 	flag.Parse()
 
-	absRootDir, err := filepath.Abs(*rootDir)
+	rootDirs = append(rootDirs, flag.Args()...)
+	if len(rootDirs) == 0 {
+		rootDirs = dirList{"."}
+	}
+
+	var absRootDirs []string
+	seen := make(map[string]bool)
+	for _, rootDir := range rootDirs {
+		absRootDir, err := filepath.Abs(rootDir)
+		if err != nil {
+			log.Fatalf("Error getting absolute path for %s: %v", rootDir, err)
+		}
+
+		// Check if directory exists
+		info, err := os.Stat(absRootDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				log.Fatalf("Error: Directory does not exist: %s", absRootDir)
+			}
+			log.Fatalf("Error accessing directory %s: %v", absRootDir, err)
+		}
+		if !info.IsDir() {
+			log.Fatalf("Error: Path is not a directory: %s", absRootDir)
+		}
+
+		if !seen[absRootDir] {
+			seen[absRootDir] = true
+			absRootDirs = append(absRootDirs, absRootDir)
+		}
+	}
+
+	// --- Load User Config ---
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// --- Initialize App State ---
+	app := internal.NewApp(absRootDirs) // isLoading is true initially
+
+	if *splitTokens != 0 {
+		cfg.SplitTokens = *splitTokens
+	}
+	if *splitBytes != 0 {
+		cfg.SplitBytes = *splitBytes
+	}
+	if cfg.SplitTokens < 0 || cfg.SplitBytes < 0 {
+		log.Fatalf("Error: split limits must not be negative")
+	}
+	app.SetSplitLimit(internal.SplitLimit{Tokens: cfg.SplitTokens, Bytes: cfg.SplitBytes})
+
+	if *truncate != "" {
+		cfg.Truncate = *truncate
+	}
+	truncation, err := internal.ParseTruncation(cfg.Truncate)
 	if err != nil {
-		log.Fatalf("Error getting absolute path for %s: %v", *rootDir, err)
+		log.Fatalf("Error: %v", err)
+	}
+	app.SetTruncation(truncation)
+	if cfg.StackWidth > 0 {
+		app.SetStackWidth(cfg.StackWidth)
 	}
 
-	// Check if directory exists
-	info, err := os.Stat(absRootDir)
+	if *theme != "" {
+		cfg.Theme = *theme
+	}
+	if *syntaxTheme != "" {
+		cfg.SyntaxTheme = *syntaxTheme
+	}
+	if internal.NoColor() {
+		cfg.Theme, cfg.SyntaxTheme = internal.ThemeMonochrome, "none"
+	}
+	uiTheme, err := internal.LoadTheme(cfg.Theme, cfg.Themes)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	app.SetTheme(uiTheme)
+	if cfg.SyntaxTheme == "" {
+		cfg.SyntaxTheme = uiTheme.SyntaxTheme
+	}
+
+	// 256 colours unless the terminal advertises 24-bit colour.
+	trueColor := os.Getenv("COLORTERM") == "truecolor" || os.Getenv("COLORTERM") == "24bit"
+	highlighter, err := internal.NewHighlighter(cfg.SyntaxTheme, cfg.HighlightMaxBytes, trueColor)
 	if err != nil {
-		if os.IsNotExist(err) {
-			log.Fatalf("Error: Directory does not exist: %s", absRootDir)
+		log.Fatalf("Error: %v", err)
+	}
+	app.SetHighlighter(highlighter)
+
+	if *symlinks != "" || *symlinkEscape {
+		policy := app.SymlinkPolicy()
+		if *symlinks != "" {
+			var err error
+			policy, err = internal.ParseSymlinkPolicy(*symlinks)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
 		}
-		log.Fatalf("Error accessing directory %s: %v", absRootDir, err)
+		app.SetSymlinkPolicy(policy, *symlinkEscape)
 	}
-	if !info.IsDir() {
-		log.Fatalf("Error: Path is not a directory: %s", absRootDir)
+
+	// Applied whenever given, so --line-numbers=false overrides a cached true.
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "line-numbers" {
+			app.SetLineNumbers(*lineNumbers)
+		}
+	})
+
+	if *importDepth < 0 {
+		log.Fatalf("Error: --import-depth must not be negative")
 	}
+	app.SetImportDepth(*importDepth)
 
-	// --- Initialize App State ---
-	app := internal.NewApp(absRootDir) // isLoading is true initially
+	if *order != "" {
+		strategy, err := internal.ParseOrderStrategy(*order)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		app.SetOrderStrategy(strategy)
+	}
+
+	if *transforms != "" {
+		parsed, err := internal.ParseTransforms(*transforms)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		app.SetTransforms(parsed)
+	}
 
 	// --- Load Gitignore (Synchronous, relatively fast) ---
-	matcher, err := internal.LoadGitignoreMatcher(app.RootDir())
+	// Each root gets its own matcher, relative to that root.
+	for _, rootDir := range app.RootDirs() {
+		matcher, err := internal.LoadGitignoreMatcher(rootDir)
+		if err != nil {
+			log.Printf("Warning: Failed to parse .gitignore in %s: %v", rootDir, err)
+		} else if matcher != nil {
+			app.SetGitignoreMatcher(rootDir, matcher)
+		} else {
+			log.Printf("Info: No .gitignore file found or parsed in %s", rootDir)
+		}
+	}
+
+	// --- Read Pre-selection List (before the TUI takes over the terminal) ---
+	var selectPaths []string
+	if *selectFrom != "" {
+		var err error
+		selectPaths, err = internal.ReadPathList(*selectFrom)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	// --- Headless Mode ---
+	if *headless {
+		if err := app.ListFiles(); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		app.SetLoadingComplete(nil)
+
+		if *selectFrom != "" {
+			report := app.SelectPaths(selectPaths, *selectFrom)
+			for _, path := range report.Missing {
+				fmt.Fprintf(os.Stderr, "Warning: not found: %s\n", path)
+			}
+			for _, path := range report.Rejected {
+				fmt.Fprintf(os.Stderr, "Warning: filtered out: %s\n", path)
+			}
+			if report.Selected == 0 {
+				log.Fatalf("Error: none of the %d path(s) from %s could be selected", report.Requested, report.Source)
+			}
+		}
+
+		count, redacted, err := app.WriteBundle(os.Stdout, *templateName)
+		if err != nil {
+			log.Fatalf("Error writing bundle: %v", err)
+		}
+		if len(redacted) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: secrets redacted in: %s\n", strings.Join(redacted, ", "))
+		}
+		fmt.Fprintf(os.Stderr, "Bundled %d file(s).\n", count)
+		return
+	}
+
+	// The clipboard is only used interactively: headless runs write to
+	// stdout, so a broken clipboard setting must not stop them.
+	if *clipboardBackend != "" {
+		cfg.Clipboard = *clipboardBackend
+	}
+	if *clipboardFile != "" {
+		cfg.ClipboardFile = *clipboardFile
+	}
+	if *clipboardCommand != "" {
+		cfg.ClipboardCommand = *clipboardCommand
+	}
+	clipboards, err := internal.NewClipboardChain(internal.ClipboardConfig{
+		Backend:  cfg.Clipboard,
+		Fallback: cfg.ClipboardFallback,
+		File:     cfg.ClipboardFile,
+		Command:  cfg.ClipboardCommand,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	app.SetClipboards(clipboards)
+
+	keymap, err := internal.LoadKeymap()
 	if err != nil {
-		log.Printf("Warning: Failed to parse .gitignore: %v", err)
-	} else if matcher != nil {
-		app.SetGitignoreMatcher(matcher)
-	} else {
-		log.Printf("Info: No .gitignore file found or parsed in %s", app.RootDir())
+		log.Fatalf("Error: %v", err)
+	}
+	if err := app.SetKeymap(keymap); err != nil {
+		log.Fatalf("Error: %v", err)
 	}
 
 	// --- Initialize gocui ---
-	g, err := gocui.NewGui(gocui.OutputNormal, true)
+	outputMode := gocui.Output256 // Needed for the syntax highlighting escapes
+	if trueColor {
+		outputMode = gocui.OutputTrue
+	}
+	g, err := gocui.NewGui(outputMode, true)
 	if err != nil {
 		log.Panicln(err)
 	}
 	defer g.Close()
 
 	g.Highlight = true
-	g.SelFgColor = gocui.ColorMagenta
+	g.SelFgColor = uiTheme.Highlight
 	g.SelBgColor = gocui.ColorDefault
 	g.Cursor = true
+	g.Mouse = !*noMouse && !cfg.NoMouse
 
 	// --- Configure and Run App ---
 	app.SetGui(g)
@@ -69,6 +289,10 @@ func main() {
 	// --- Start Asynchronous File Loading ---
 	go func() {
 		err := app.ListFiles()
+		if err == nil && *selectFrom != "" {
+			report := app.SelectPaths(selectPaths, *selectFrom)
+			app.SetStatusMessage(report.Summary())
+		}
 
 		app.SetLoadingComplete(err)
 
@@ -84,4 +308,5 @@ func main() {
 	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
 		log.Panicln(fmt.Sprintf("Error in main loop: %v", err))
 	}
+	app.FlushLayout()
 }